@@ -0,0 +1,125 @@
+// Package batch runs a feature over many newline-delimited inputs with a
+// bounded worker pool while keeping the output in input order.
+package batch
+
+import (
+	"bhelper/feature"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// maxLineSize bounds a single input line
+const maxLineSize = 1 << 20
+
+// Func runs a feature on one input
+type Func func(ctx context.Context, input string) (*feature.Result, error)
+
+// Outcome is the result of one input line. Err is set when the feature
+// failed on that line.
+type Outcome struct {
+	Line   int
+	Input  string
+	Result *feature.Result
+	Err    error
+}
+
+// job is an input waiting for a worker, with the channel its outcome is
+// delivered on
+type job struct {
+	line  int
+	input string
+	done  chan Outcome
+}
+
+// Run reads inputs from r, one per line, and runs fn on each with at most
+// workers concurrent calls. Blank lines are skipped. Outcomes are passed to
+// emit in input order and a failing input does not stop the others. Run
+// returns the first read or emit error, or the context error when ctx is
+// cancelled before every line is processed.
+func Run(ctx context.Context, r io.Reader, workers int, fn Func, emit func(Outcome) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan job)
+	// Bounds the outcomes buffered while waiting for an earlier, slower line
+	pending := make(chan job, workers*4)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				result, err := fn(ctx, j.input)
+				j.done <- Outcome{Line: j.line, Input: j.input, Result: result, Err: err}
+			}
+		}()
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		readErr <- read(ctx, r, jobs, pending)
+	}()
+
+	var emitErr error
+	for j := range pending {
+		o := <-j.done
+		if emitErr == nil {
+			if emitErr = emit(o); emitErr != nil {
+				cancel()
+			}
+		}
+	}
+	wg.Wait()
+
+	if emitErr != nil {
+		return emitErr
+	}
+	if err := <-readErr; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// read queues every non-blank line of r, stopping early when ctx is done
+func read(ctx context.Context, r io.Reader, jobs, pending chan<- job) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		input := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(input) == "" {
+			continue
+		}
+
+		j := job{line: line, input: input, done: make(chan Outcome, 1)}
+		select {
+		case pending <- j:
+		case <-ctx.Done():
+			return nil
+		}
+		select {
+		case jobs <- j:
+		case <-ctx.Done():
+			j.done <- Outcome{Line: line, Input: input, Err: ctx.Err()}
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read line %d: %w", line+1, err)
+	}
+	return nil
+}
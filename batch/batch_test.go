@@ -0,0 +1,141 @@
+package batch
+
+import (
+	"bhelper/feature"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// square parses a number and returns its square, sleeping longer for
+// smaller numbers so that results finish out of order
+func square(ctx context.Context, input string) (*feature.Result, error) {
+	n, err := strconv.Atoi(input)
+	if err != nil {
+		return nil, fmt.Errorf("not a number: %q", input)
+	}
+	time.Sleep(time.Duration(10-n%10) * time.Millisecond)
+
+	result := feature.NewResult("")
+	result.AddSection("").Add("square", "Square", n*n)
+	return result, nil
+}
+
+func TestRunPreservesOrder(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 50; i++ {
+		lines = append(lines, strconv.Itoa(i))
+	}
+
+	var got []int
+	err := Run(context.Background(), strings.NewReader(strings.Join(lines, "\n")), 8, square, func(o Outcome) error {
+		if o.Err != nil {
+			t.Errorf("Unexpected error on line %d: %v", o.Line, o.Err)
+		}
+		got = append(got, o.Line)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(got) != 50 {
+		t.Fatalf("Expected 50 outcomes, got %d", len(got))
+	}
+	for i, line := range got {
+		if line != i+1 {
+			t.Fatalf("Expected line %d at position %d, got %d", i+1, i, line)
+		}
+	}
+}
+
+func TestRunContinuesAfterFailures(t *testing.T) {
+	input := "1\nx\n\n3\r\n  \ny\n"
+
+	var outcomes []Outcome
+	err := Run(context.Background(), strings.NewReader(input), 2, square, func(o Outcome) error {
+		outcomes = append(outcomes, o)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := []struct {
+		line   int
+		input  string
+		failed bool
+	}{
+		{1, "1", false},
+		{2, "x", true},
+		{4, "3", false},
+		{6, "y", true},
+	}
+	if len(outcomes) != len(want) {
+		t.Fatalf("Expected %d outcomes, got %+v", len(want), outcomes)
+	}
+	for i, w := range want {
+		o := outcomes[i]
+		if o.Line != w.line || o.Input != w.input || (o.Err != nil) != w.failed {
+			t.Errorf("Outcome %d: got line %d input %q err %v, want %+v", i, o.Line, o.Input, o.Err, w)
+		}
+	}
+}
+
+func TestRunBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	fn := func(ctx context.Context, input string) (*feature.Result, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		return feature.NewResult(""), nil
+	}
+
+	input := strings.Repeat("a\n", 40)
+	if err := Run(context.Background(), strings.NewReader(input), 3, fn, func(Outcome) error { return nil }); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if peak.Load() > 3 {
+		t.Errorf("Expected at most 3 concurrent runs, got %d", peak.Load())
+	}
+}
+
+func TestRunStopsOnEmitError(t *testing.T) {
+	stop := errors.New("disk full")
+
+	count := 0
+	err := Run(context.Background(), strings.NewReader(strings.Repeat("1\n", 100)), 4, square, func(Outcome) error {
+		count++
+		if count == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected emit error, got %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected emitting to stop after the error, got %d calls", count)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Run(ctx, strings.NewReader(strings.Repeat("1\n", 100)), 2, square, func(Outcome) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package batch
+
+import (
+	"bhelper/feature"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format is an output format for batch results
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+)
+
+// Formats lists all supported batch output formats
+var Formats = []Format{FormatJSONL, FormatCSV}
+
+// ParseFormat converts a format name into a Format
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case FormatJSONL, FormatCSV:
+		return Format(name), nil
+	}
+	return "", fmt.Errorf("unknown batch output format %q (expected jsonl or csv)", name)
+}
+
+// Writer writes outcomes as they arrive
+type Writer interface {
+	Write(o Outcome) error
+	// Flush writes any buffered data
+	Flush() error
+}
+
+// NewWriter creates a writer for the given format
+func NewWriter(w io.Writer, format Format, featureID string) (Writer, error) {
+	switch format {
+	case FormatJSONL:
+		return &jsonlWriter{w: w, featureID: featureID}, nil
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown batch output format %q", format)
+	}
+}
+
+// Record is a JSON line of batch output. Text holds the rendered result
+// when it has no fields, such as results made of text or tables.
+type Record struct {
+	Line    int            `json:"line"`
+	Feature string         `json:"feature"`
+	Input   string         `json:"input"`
+	Values  map[string]any `json:"values,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// resultText renders a result without fields, whose content would
+// otherwise be lost. Results with fields yield "".
+func resultText(r *feature.Result) string {
+	if len(r.Fields()) > 0 {
+		return ""
+	}
+	return strings.TrimRight(r.String(), "\n")
+}
+
+// jsonlWriter writes one JSON record per input
+type jsonlWriter struct {
+	w         io.Writer
+	featureID string
+}
+
+// Write encodes the record before writing it, so a result that cannot be
+// encoded is reported on its line instead of failing the batch
+func (w *jsonlWriter) Write(o Outcome) error {
+	rec := Record{Line: o.Line, Feature: w.featureID, Input: o.Input}
+	if o.Err != nil {
+		rec.Error = o.Err.Error()
+	} else if o.Result != nil {
+		rec.Values = feature.NewDocument(w.featureID, o.Input, o.Result).Values
+		rec.Text = resultText(o.Result)
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		rec = Record{Line: o.Line, Feature: w.featureID, Input: o.Input, Error: fmt.Sprintf("failed to encode result: %v", err)}
+		if data, err = json.Marshal(rec); err != nil {
+			return err
+		}
+	}
+	_, err = w.w.Write(append(data, '\n'))
+	return err
+}
+
+func (w *jsonlWriter) Flush() error {
+	return nil
+}
+
+// csvHeader is the header row of CSV batch output
+var csvHeader = []string{"line", "input", "key", "value", "text", "error"}
+
+// csvWriter writes one row per result field, or a single row holding the
+// error of a failed input or the text of a result without fields
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvWriter) Write(o Outcome) error {
+	if !w.headerWritten {
+		if err := w.w.Write(csvHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	line := strconv.Itoa(o.Line)
+	if o.Err != nil {
+		return w.w.Write([]string{line, o.Input, "", "", "", o.Err.Error()})
+	}
+	if o.Result == nil {
+		return nil
+	}
+	if text := resultText(o.Result); text != "" {
+		return w.w.Write([]string{line, o.Input, "", "", text, ""})
+	}
+
+	for _, f := range o.Result.Fields() {
+		if err := w.w.Write([]string{line, o.Input, f.Key, feature.FormatValue(f.Value), "", ""}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *csvWriter) Flush() error {
+	if !w.headerWritten {
+		if err := w.w.Write(csvHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
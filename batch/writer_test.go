@@ -0,0 +1,125 @@
+package batch
+
+import (
+	"bhelper/feature"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func sampleOutcomes() []Outcome {
+	result := feature.NewResult("")
+	result.AddSection("").Add("runes", "Runes", 2).Add("text", "Text", "a,b")
+	return []Outcome{
+		{Line: 1, Input: "ab", Result: result},
+		{Line: 3, Input: "??", Err: errors.New("bad input")},
+	}
+}
+
+func write(t *testing.T, format Format, outcomes []Outcome) string {
+	t.Helper()
+
+	var b strings.Builder
+	w, err := NewWriter(&b, format, "character")
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	for _, o := range outcomes {
+		if err := w.Write(o); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	return b.String()
+}
+
+func TestWriterJSONL(t *testing.T) {
+	got := write(t, FormatJSONL, sampleOutcomes())
+	want := `{"line":1,"feature":"character","input":"ab","values":{"runes":2,"text":"a,b"}}
+{"line":3,"feature":"character","input":"??","error":"bad input"}
+`
+	if got != want {
+		t.Errorf("Unexpected JSONL output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriterCSV(t *testing.T) {
+	got := write(t, FormatCSV, sampleOutcomes())
+	want := `line,input,key,value,text,error
+1,ab,runes,2,,
+1,ab,text,"a,b",,
+3,??,,,,bad input
+`
+	if got != want {
+		t.Errorf("Unexpected CSV output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriterCSVEmpty(t *testing.T) {
+	if got := write(t, FormatCSV, nil); got != "line,input,key,value,text,error\n" {
+		t.Errorf("Expected only the header, got %q", got)
+	}
+}
+
+func TestWriterTextResult(t *testing.T) {
+	outcomes := []Outcome{{Line: 2, Input: "x", Result: feature.TextResult("first line\nsecond line\n")}}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatJSONL, `{"line":2,"feature":"character","input":"x","text":"first line\nsecond line"}` + "\n"},
+		{FormatCSV, "line,input,key,value,text,error\n2,x,,,\"first line\nsecond line\",\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			if got := write(t, tt.format, outcomes); got != tt.want {
+				t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterJSONLUnencodableResult(t *testing.T) {
+	infinite := feature.NewResult("")
+	infinite.AddSection("").Add("hours", "Hours", math.Inf(1))
+	unencodable := feature.NewResult("")
+	unencodable.AddSection("").Add("done", "Done", make(chan int))
+
+	outcomes := sampleOutcomes()
+	outcomes = append(outcomes,
+		Outcome{Line: 4, Input: "1e308h", Result: infinite},
+		Outcome{Line: 5, Input: "chan", Result: unencodable},
+		Outcome{Line: 6, Input: "cd", Result: outcomes[0].Result},
+	)
+
+	lines := strings.Split(strings.TrimSuffix(write(t, FormatJSONL, outcomes), "\n"), "\n")
+	if len(lines) != len(outcomes) {
+		t.Fatalf("Expected a record per input, got:\n%s", strings.Join(lines, "\n"))
+	}
+	for i, line := range lines {
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil || rec.Line != outcomes[i].Line {
+			t.Errorf("Expected the record of line %d, got %s", outcomes[i].Line, line)
+		}
+	}
+
+	want := `{"line":5,"feature":"character","input":"chan","error":"failed to encode result: json: unsupported type: chan int"}`
+	if lines[3] != want {
+		t.Errorf("Expected an error record, got %s", lines[3])
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("csv"); err != nil || f != FormatCSV {
+		t.Errorf("Expected csv, got %q (%v)", f, err)
+	}
+	if _, err := ParseFormat("json"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
@@ -2,11 +2,22 @@ package main
 
 import (
 	"bhelper/feature"
+	"bhelper/recording"
+	"bhelper/store"
+	"bhelper/theme"
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // CLIMode represents the current state of the CLI
@@ -16,41 +27,196 @@ const (
 	ModeFeatureList CLIMode = iota
 	ModeFeatureHelp
 	ModeFeatureExecute
+	ModeCompare
 )
 
 // CLI is the main TUI model
 type CLI struct {
 	registry        *feature.FeatureRegistry
+	features        []feature.Feature // Registered features in list order
 	mode            CLIMode
 	selectedIndex   int
 	selectedFeature feature.Feature
+	filterInput     textinput.Model
+	filtering       bool
 	textInput       textinput.Model
+	textArea        textarea.Model
+	loadingFile     bool
+	loadPath        textinput.Model
 	output          string
+	result          *feature.Result
+	resultInput     string
+	format          feature.Format
 	history         *History
+	inputHistory    *store.InputHistory
+	usage           *store.Usage
+	historyIndex    int
+	historyDraft    string
+	searching       bool
+	searchQuery     string
+	searchIndex     int
+	presets         *store.Presets
+	presetMode      presetMode
+	presetIndex     int
+	presetName      textinput.Model
+	exportMode      exportMode
+	copyIndex       int
+	savePath        textinput.Model
+	saveFormat      feature.Format
+	sendMode        sendMode
+	sendIndex       int
+	sendField       int
+	viewport        viewport.Model
+	outputFocused   bool
+	outputSearching bool
+	outputQuery     textinput.Model
+	outputMatches   []int
+	outputMatch     int
+	completionIndex int
+	hideCompletion  bool // Suggestions stay hidden until the next keystroke
+	compare         [2]comparePane
+	compareFocus    int
+	compareOffset   int
+	width           int
+	height          int
+	inputWidth      int
+	status          string
+	spinner         spinner.Model
+	progressBar     progress.Model
+	progress        float64
+	running         bool
+	runID           int
+	runSeq          int // Last run ID handed out to any tab
+	recordRun       int // Run to add to the session log once it completes
+	recorder        *recording.Recorder
+	terminal        *terminalOutput // Where the interface is drawn, nil when unknown
+	cancelRun       context.CancelFunc
+	timeouts        timeoutPolicy
+	liveDebounce    time.Duration
+	editSeq         int
+	tabs            []tab // The tab in front lives in the fields above
+	activeTab       int
+	undoSize        int
+	keys            keyMap
+	themes          []theme.Theme
+	themeIndex      int
+	styles          styles
+}
+
+// cliOptions configures a CLI
+type cliOptions struct {
+	timeouts     timeoutPolicy
+	inputHistory *store.InputHistory
+	presets      *store.Presets
+	keys         keyMap
+	undoSize     int
+	inputWidth   int
+	liveDebounce time.Duration
+	themes       []theme.Theme
+	theme        string
+	featureOrder feature.SortOrder
+	usage        *store.Usage
+	session      *store.Session
+	recorder     *recording.Recorder
+	terminal     *terminalOutput
 }
 
 // NewCLI creates a new CLI instance
-func NewCLI(registry *feature.FeatureRegistry) CLI {
+func NewCLI(registry *feature.FeatureRegistry, opts cliOptions) CLI {
 	ti := textinput.New()
 	ti.Placeholder = "Type your input..."
-	ti.Width = 70
+	ti.Width = opts.inputWidth
+	if ti.Width == 0 {
+		ti.Width = defaultInputWidth
+	}
+
+	fi := textinput.New()
+	fi.Prompt = "/"
+	fi.Placeholder = "filter features..."
+
+	pn := textinput.New()
+	pn.Placeholder = "label for the current input"
+
+	sv := textinput.New()
+	sv.Placeholder = "file name"
+
+	lp := textinput.New()
+	lp.Placeholder = "path to a file"
+
+	oq := textinput.New()
+	oq.Prompt = "/"
+	oq.Placeholder = "search output"
+
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
 
-	return CLI{
+	c := CLI{
 		registry:      registry,
+		features:      registry.Sorted(opts.featureOrder, usageCounts(opts.usage)),
 		mode:          ModeFeatureList,
 		selectedIndex: 0,
+		filterInput:   fi,
 		textInput:     ti,
-		history:       NewHistory(50),
+		textArea:      newTextArea(),
+		loadPath:      lp,
+		history:       NewHistory(opts.undoSize),
+		undoSize:      opts.undoSize,
+		inputHistory:  opts.inputHistory,
+		usage:         opts.usage,
+		recorder:      opts.recorder,
+		terminal:      opts.terminal,
+		historyIndex:  -1,
+		presets:       opts.presets,
+		presetName:    pn,
+		savePath:      sv,
+		viewport:      viewport.New(0, 0),
+		outputQuery:   oq,
+		inputWidth:    opts.inputWidth,
+		format:        feature.FormatText,
+		spinner:       sp,
+		timeouts:      opts.timeouts,
+		liveDebounce:  opts.liveDebounce,
+		keys:          opts.keys,
+		themes:        opts.themes,
 	}
+	if len(c.themes) == 0 {
+		c.themes = theme.Builtin()
+	}
+	c.applyTheme(max(theme.Index(opts.themes, opts.theme), 0))
+	c.tabs = []tab{c.saveTab()}
+	c.restoreTabs(opts.session)
+	return c
 }
 
 func (c CLI) Init() tea.Cmd {
-	return textinput.Blink
+	// A restored live feature shows its result right away
+	if c.liveReady() {
+		return tea.Batch(textinput.Blink, liveTick(0, c.editSeq), warmCompletions(c.registry.List()))
+	}
+	return tea.Batch(textinput.Blink, warmCompletions(c.registry.List()))
 }
 
 func (c CLI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if key.Matches(msg, c.keys.Theme) {
+			return c.nextTheme()
+		}
+
+		// The compare mode keeps its panes to itself until it is closed
+		if c.mode != ModeCompare {
+			switch {
+			case key.Matches(msg, c.keys.NewTab):
+				return c.openTab()
+			case key.Matches(msg, c.keys.NextTab):
+				return c.switchTab((c.activeTab + 1) % len(c.tabs))
+			case key.Matches(msg, c.keys.PrevTab):
+				return c.switchTab((c.activeTab + len(c.tabs) - 1) % len(c.tabs))
+			case key.Matches(msg, c.keys.CloseTab):
+				return c.closeTab()
+			}
+		}
+
 		switch c.mode {
 		case ModeFeatureList:
 			return c.updateFeatureList(msg)
@@ -58,6 +224,50 @@ func (c CLI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return c.updateFeatureHelp(msg)
 		case ModeFeatureExecute:
 			return c.updateFeatureExecute(msg)
+		case ModeCompare:
+			return c.updateCompare(msg)
+		}
+
+	case liveTickMsg:
+		if msg.seq != c.editSeq {
+			break
+		}
+		switch c.mode {
+		case ModeFeatureExecute:
+			return c.startExecution()
+		case ModeCompare:
+			return c.runCompare()
+		}
+
+	case resultMsg:
+		if i := c.tabOfRun(msg.runID); i >= 0 {
+			return c.inTab(i, func(c CLI) CLI {
+				c, _ = c.handleResult(msg)
+				return c
+			}), nil
+		}
+		if c.mode == ModeCompare {
+			return c.handleCompareResult(msg)
+		}
+		return c.handleResult(msg)
+
+	case progressMsg:
+		return c.handleProgress(msg)
+
+	case clipboardMsg:
+		return c.handleClipboard(msg)
+
+	case tea.WindowSizeMsg:
+		return c.resize(msg)
+
+	case tea.MouseMsg:
+		return c.updateOutputMouse(msg)
+
+	case spinner.TickMsg:
+		if c.running || (c.mode == ModeCompare && c.compareRunning()) {
+			var cmd tea.Cmd
+			c.spinner, cmd = c.spinner.Update(msg)
+			return c, cmd
 		}
 	}
 	return c, nil
@@ -65,49 +275,139 @@ func (c CLI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // updateFeatureList handles feature list navigation
 func (c CLI) updateFeatureList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	features := c.registry.List()
+	c.status = ""
+
+	if c.filtering {
+		return c.updateFilter(msg)
+	}
 
-	switch msg.String() {
-	case "ctrl+c", "q":
+	entries := c.visibleFeatures()
+
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Quit):
 		return c, tea.Quit
 
-	case "up", "k":
+	case key.Matches(msg, c.keys.Filter):
+		c.filtering = true
+		c.filterInput.Focus()
+		return c, textinput.Blink
+
+	case key.Matches(msg, c.keys.Back):
+		c.filterInput.SetValue("")
+		c.selectedIndex = 0
+
+	case key.Matches(msg, c.keys.Up):
 		if c.selectedIndex > 0 {
 			c.selectedIndex--
 		}
 
-	case "down", "j":
-		if c.selectedIndex < len(features)-1 {
+	case key.Matches(msg, c.keys.Down):
+		if c.selectedIndex < len(entries)-1 {
 			c.selectedIndex++
 		}
 
-	case "enter":
-		c.selectedFeature = features[c.selectedIndex]
-		c.mode = ModeFeatureExecute
-		c.textInput.Focus()
-		return c, textinput.Blink
+	case key.Matches(msg, c.keys.Select):
+		if len(entries) > 0 {
+			return c.openFeature(entries[c.selectedIndex].feature)
+		}
 
-	case "h", "?":
-		c.selectedFeature = features[c.selectedIndex]
-		c.mode = ModeFeatureHelp
+	case key.Matches(msg, c.keys.Help):
+		if len(entries) > 0 {
+			c.selectedFeature = entries[c.selectedIndex].feature
+			c.mode = ModeFeatureHelp
+		}
+
+	case key.Matches(msg, c.keys.Pin):
+		if len(entries) > 0 {
+			id := entries[c.selectedIndex].feature.ID()
+			c.toggleFavorite(id)
+			c.selectedIndex = c.indexOf(id)
+		}
 	}
 
 	return c, nil
 }
 
+// updateFilter handles typing in the feature list filter
+func (c CLI) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	entries := c.visibleFeatures()
+
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit):
+		return c, tea.Quit
+
+	case key.Matches(msg, c.keys.Back):
+		c.filtering = false
+		c.filterInput.Blur()
+		c.filterInput.SetValue("")
+		c.selectedIndex = 0
+		return c, nil
+
+	case msg.Type == tea.KeyUp || msg.Type == tea.KeyCtrlP:
+		if c.selectedIndex > 0 {
+			c.selectedIndex--
+		}
+		return c, nil
+
+	case msg.Type == tea.KeyDown || msg.Type == tea.KeyCtrlN:
+		if c.selectedIndex < len(entries)-1 {
+			c.selectedIndex++
+		}
+		return c, nil
+
+	case key.Matches(msg, c.keys.Select):
+		c.filtering = false
+		c.filterInput.Blur()
+		if len(entries) > 0 {
+			return c.openFeature(entries[c.selectedIndex].feature)
+		}
+		return c, nil
+	}
+
+	oldValue := c.filterInput.Value()
+	var cmd tea.Cmd
+	c.filterInput, cmd = c.filterInput.Update(msg)
+	if oldValue != c.filterInput.Value() {
+		c.selectedIndex = 0
+	}
+	return c, cmd
+}
+
+// openFeature switches to the execute view for a feature. A run still
+// going for the previous feature is cancelled so its result cannot show up
+// here.
+func (c CLI) openFeature(f feature.Feature) (tea.Model, tea.Cmd) {
+	c.cancelExecution()
+	c.clearOutput()
+	c.selectedFeature = f
+	c.mode = ModeFeatureExecute
+	return c, c.focusInput()
+}
+
+// backToList returns to the feature list, dropping the input and any run
+// of the feature that was open
+func (c CLI) backToList() CLI {
+	c.mode = ModeFeatureList
+	c.blurInput()
+	c.setInput("")
+	c.historyIndex = -1
+	c.cancelExecution()
+	c.clearOutput()
+	return c
+}
+
 // updateFeatureHelp handles help screen
 func (c CLI) updateFeatureHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c", "q":
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Quit):
 		return c, tea.Quit
 
-	case "esc", "backspace":
-		c.mode = ModeFeatureList
+	case key.Matches(msg, c.keys.Back), msg.Type == tea.KeyBackspace:
+		return c.backToList(), nil
 
-	case "enter":
+	case key.Matches(msg, c.keys.Select):
 		c.mode = ModeFeatureExecute
-		c.textInput.Focus()
-		return c, textinput.Blink
+		return c, c.focusInput()
 	}
 
 	return c, nil
@@ -115,62 +415,166 @@ func (c CLI) updateFeatureHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // updateFeatureExecute handles feature execution
 func (c CLI) updateFeatureExecute(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
+	c.status = ""
+
+	if c.searching {
+		return c.updateSearch(msg)
+	}
+	if c.presetMode != presetsClosed {
+		return c.updatePresets(msg)
+	}
+	if c.exportMode != exportClosed {
+		return c.updateExport(msg)
+	}
+	if c.loadingFile {
+		return c.updateLoadFile(msg)
+	}
+	if c.sendMode != sendClosed {
+		return c.updateSend(msg)
+	}
+	if c.outputFocused {
+		return c.updateOutput(msg)
+	}
+
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit):
+		if c.running {
+			c.cancelExecution()
+			c.clearOutput()
+			c.output = "Cancelled"
+			return c, nil
+		}
 		return c, tea.Quit
 
-	case "esc":
-		c.mode = ModeFeatureList
-		c.textInput.Blur()
-		c.textInput.SetValue("")
-		c.output = ""
+	// Suggestions take the completion, navigation and back keys while shown
+	case key.Matches(msg, c.keys.Complete) && c.completing():
+		return c.acceptCompletion()
+
+	case key.Matches(msg, c.keys.HistoryPrev) && c.completing():
+		return c.moveCompletion(-1), nil
+
+	case key.Matches(msg, c.keys.HistoryNext) && c.completing():
+		return c.moveCompletion(1), nil
+
+	case key.Matches(msg, c.keys.Back) && c.completing():
+		c.hideCompletion = true
 		return c, nil
 
-	case "ctrl+h":
+	case key.Matches(msg, c.keys.Back):
+		if c.running {
+			c.cancelExecution()
+			c.clearOutput()
+			c.output = "Cancelled"
+			return c, nil
+		}
+
+		return c.backToList(), nil
+
+	case key.Matches(msg, c.keys.FeatureHelp):
 		c.mode = ModeFeatureHelp
-		c.textInput.Blur()
+		c.blurInput()
 		return c, nil
 
-	case "enter":
-		// Execute the feature
-		input := c.textInput.Value()
-		if input != "" {
-			result, err := c.selectedFeature.Execute(input)
-			if err != nil {
-				c.output = fmt.Sprintf("Error: %v", err)
-			} else {
-				c.output = result
-			}
+	// Enter adds a line break in multi-line input
+	case key.Matches(msg, c.keys.ExecuteMultiline), !c.multiline() && key.Matches(msg, c.keys.Execute):
+		c.recordInput(c.inputValue())
+		return c.startRecordedExecution()
+
+	case key.Matches(msg, c.keys.HistoryPrev) && c.atFirstLine():
+		return c.browseHistory(-1)
+
+	case key.Matches(msg, c.keys.HistoryNext) && c.atLastLine():
+		return c.browseHistory(1)
+
+	case key.Matches(msg, c.keys.HistorySearch):
+		return c.startSearch()
+
+	case key.Matches(msg, c.keys.Presets):
+		return c.openPresets()
+
+	case key.Matches(msg, c.keys.LoadFile):
+		return c.openLoadFile()
+
+	case key.Matches(msg, c.keys.FocusOutput):
+		return c.focusOutput()
+
+	case key.Matches(msg, c.keys.PageUp):
+		return c.scrollOutput(func(c *CLI) { c.viewport.PageUp() })
+
+	case key.Matches(msg, c.keys.PageDown):
+		return c.scrollOutput(func(c *CLI) { c.viewport.PageDown() })
+
+	case key.Matches(msg, c.keys.Copy):
+		return c.openCopy()
+
+	case key.Matches(msg, c.keys.Save):
+		return c.openSave()
+
+	case key.Matches(msg, c.keys.Send):
+		return c.openSend()
+
+	case key.Matches(msg, c.keys.Compare):
+		return c.openCompare()
+
+	case key.Matches(msg, c.keys.OutputFormat):
+		c.format = c.format.Next()
+		if c.result != nil {
+			c.output = c.renderOutput()
 		}
 		return c, nil
 
-	case "ctrl+z":
+	case key.Matches(msg, c.keys.Undo):
 		if state := c.history.Undo(); state != nil {
-			c.textInput.SetValue(*state)
-			c.output = ""
+			c.setInput(*state)
+			return c.inputChanged()
 		}
 		return c, nil
 
-	case "ctrl+y":
+	case key.Matches(msg, c.keys.Redo):
 		if state := c.history.Redo(); state != nil {
-			c.textInput.SetValue(*state)
-			c.output = ""
+			c.setInput(*state)
+			return c.inputChanged()
 		}
 		return c, nil
 
 	default:
-		oldValue := c.textInput.Value()
+		oldValue := c.inputValue()
 		var cmd tea.Cmd
-		c.textInput, cmd = c.textInput.Update(msg)
+		c, cmd = c.updateInput(msg)
 
-		if oldValue != c.textInput.Value() {
+		if oldValue != c.inputValue() {
 			c.history.Push(oldValue)
-			c.output = ""
+			c.historyIndex = -1
+			var liveCmd tea.Cmd
+			c, liveCmd = c.inputChanged()
+			c.hideCompletion = false
+			return c, tea.Batch(cmd, liveCmd)
 		}
 		return c, cmd
 	}
 }
 
+// renderOutput renders the last result in the selected output format
+func (c CLI) renderOutput() string {
+	if c.format == feature.FormatText {
+		return c.styles.renderResult(c.result)
+	}
+
+	out, err := c.encodeResult(c.format)
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return out
+}
+
+// clearOutput discards the last result
+func (c *CLI) clearOutput() {
+	c.output = ""
+	c.result = nil
+	c.viewport.YOffset = 0
+	c.outputFocused = false
+}
+
 func (c CLI) View() string {
 	switch c.mode {
 	case ModeFeatureList:
@@ -179,6 +583,8 @@ func (c CLI) View() string {
 		return c.renderFeatureHelp()
 	case ModeFeatureExecute:
 		return c.renderFeatureExecute()
+	case ModeCompare:
+		return c.renderCompare()
 	}
 	return ""
 }
@@ -186,25 +592,62 @@ func (c CLI) View() string {
 // renderFeatureList shows all available features
 func (c CLI) renderFeatureList() string {
 	var s strings.Builder
+	s.WriteString(c.renderTabBar())
 
-	title := titleStyle.Render("Choose: ")
+	title := c.styles.title.Render("Choose: ")
 	s.WriteString(title + "\n")
 
-	features := c.registry.List()
-	for i, f := range features {
+	filtered := c.filterInput.Value() != ""
+	if c.filtering || filtered {
+		s.WriteString(c.filterInput.View() + "\n\n")
+	}
+
+	entries := c.visibleFeatures()
+	if len(entries) == 0 {
+		s.WriteString(c.styles.help.Render("  No matching features") + "\n")
+	}
+
+	category := ""
+	for i, e := range entries {
+		// Headings only make sense while the list is grouped
+		if !filtered && e.category != category {
+			if i > 0 {
+				s.WriteString("\n")
+			}
+			category = e.category
+			s.WriteString(c.styles.category.Render(category) + "\n")
+		}
+
 		cursor := "  "
-		style := featureStyle
+		style := c.styles.feature
 
 		if i == c.selectedIndex {
 			cursor = "→ "
-			style = selectedFeatureStyle
+			style = c.styles.selectedFeature
+		}
+
+		if e.favorite && filtered {
+			cursor += "★ "
 		}
 
-		line := fmt.Sprintf("%s%s - %s", cursor, f.Name(), f.Description())
-		s.WriteString(style.Render(line) + "\n")
+		line := style.Render(cursor) +
+			c.styles.highlight(e.feature.Name(), e.nameMatches, style) +
+			style.Render(" - ") +
+			c.styles.highlight(e.feature.Description(), e.descMatches, style)
+		s.WriteString(line + "\n")
+	}
+
+	if c.status != "" {
+		s.WriteString("\n" + c.styles.status.Render(c.status))
 	}
 
-	s.WriteString("\n" + helpStyle.Render("↑/↓: navigate • ENTER: select • H/?: help • Q: quit"))
+	k := c.keys
+	if c.filtering {
+		s.WriteString("\n" + c.styles.help.Render("↑/↓: navigate • ") + c.styles.helpLine(k.Select, withDesc(k.Back, "clear filter")))
+	} else {
+		bindings := append([]key.Binding{navHelp(k.Up, k.Down), k.Select, k.Filter, k.Pin, k.Help}, c.tabHelp()...)
+		s.WriteString("\n" + c.styles.helpLine(append(bindings, k.Theme, k.Quit)...))
+	}
 
 	return s.String()
 }
@@ -212,44 +655,146 @@ func (c CLI) renderFeatureList() string {
 // renderFeatureHelp shows detailed help for selected feature
 func (c CLI) renderFeatureHelp() string {
 	var s strings.Builder
+	s.WriteString(c.renderTabBar())
 
-	title := titleStyle.Render(fmt.Sprintf("📖 Help: %s", c.selectedFeature.Name()))
+	title := c.styles.title.Render(fmt.Sprintf("📖 Help: %s", c.selectedFeature.Name()))
 	s.WriteString(title + "\n\n")
 
-	s.WriteString(sectionStyle.Render("Description:") + "\n")
+	s.WriteString(c.styles.section.Render("Description:") + "\n")
 	s.WriteString(c.selectedFeature.Help() + "\n\n")
 
 	examples := c.selectedFeature.Examples()
 	if len(examples) > 0 {
-		s.WriteString(sectionStyle.Render("Examples:") + "\n")
+		s.WriteString(c.styles.section.Render("Examples:") + "\n")
 		for _, ex := range examples {
-			s.WriteString(exampleStyle.Render(fmt.Sprintf("Input: %s", ex.Input)) + "\n")
+			s.WriteString(c.styles.example.Render(fmt.Sprintf("Input: %s", ex.Input)) + "\n")
 			s.WriteString(fmt.Sprintf("  → %s\n\n", ex.Description))
 		}
 	}
 
-	s.WriteString(helpStyle.Render("ENTER: use feature • ESC: back • Q: quit"))
+	s.WriteString(c.styles.helpLine(withDesc(c.keys.Select, "use feature"), c.keys.Back, c.keys.Quit))
 
 	return s.String()
 }
 
-// renderFeatureExecute shows the feature execution interface
+// renderFeatureExecute shows the feature execution interface. The result
+// box takes whatever height the rest of the view leaves free.
 func (c CLI) renderFeatureExecute() string {
+	header := c.renderExecuteHeader()
+	footer := c.renderExecuteFooter()
+	if c.output == "" {
+		return header + footer
+	}
+
+	// The heading shows the scroll position and matches of the new layout
+	c.layoutOutput(header, footer)
+	return c.renderExecuteHeader() + c.renderOutputBox() + "\n\n" + footer
+}
+
+// renderExecuteHeader shows everything above the result box
+func (c CLI) renderExecuteHeader() string {
 	var s strings.Builder
+	s.WriteString(c.renderTabBar())
 
-	title := titleStyle.Render(fmt.Sprintf("⚡ %s", c.selectedFeature.Name()))
+	name := c.selectedFeature.Name()
+	if feature.IsLive(c.selectedFeature) {
+		name += " (live)"
+	}
+	title := c.styles.title.Render(fmt.Sprintf("⚡ %s", name))
 	s.WriteString(title + "\n\n")
 
 	// Input
-	s.WriteString(labelStyle.Render("Input: ") + c.textInput.View() + "\n\n")
+	if c.searching {
+		s.WriteString(c.renderSearch() + "\n\n")
+	} else {
+		s.WriteString(c.renderInput() + c.renderCompletion() + "\n\n")
+	}
 
 	// Output
+	if c.presetMode != presetsClosed {
+		s.WriteString(c.renderPresets() + "\n")
+	}
+	if c.exportMode != exportClosed {
+		s.WriteString(c.renderExport() + "\n")
+	}
+	if c.loadingFile {
+		s.WriteString(c.renderLoadFile() + "\n")
+	}
+	if c.sendMode != sendClosed {
+		s.WriteString(c.renderSend() + "\n")
+	}
+
+	if c.running {
+		s.WriteString(c.spinner.View() + " " + c.styles.help.Render("Running... (ESC: cancel)") + "\n")
+		if c.progress > 0 {
+			s.WriteString(c.progressBar.ViewAs(c.progress) + "\n")
+		}
+		s.WriteString("\n")
+	}
 	if c.output != "" {
-		s.WriteString(sectionStyle.Render("Result:") + "\n")
-		s.WriteString(outputBoxStyle.Render(c.output) + "\n\n")
+		s.WriteString(c.renderOutputHeading() + "\n")
 	}
 
-	s.WriteString(helpStyle.Render("ENTER: execute • CTRL+H: help • CTRL+Z: undo • CTRL+Y: redo • ESC: back"))
+	return s.String()
+}
 
+// renderExecuteFooter shows the status and help lines below the result box
+func (c CLI) renderExecuteFooter() string {
+	var s strings.Builder
+
+	if c.status != "" {
+		s.WriteString(c.styles.status.Render(c.status) + "\n")
+	}
+
+	switch {
+	case c.searching:
+		s.WriteString(c.styles.help.Render("type to search • ") +
+			c.styles.helpLine(withDesc(c.keys.HistorySearch, "older match"), newBinding("accept", "enter"), withDesc(c.keys.Back, "cancel")))
+	case c.presetMode == presetsNaming:
+		s.WriteString(c.styles.helpLine(withDesc(c.keys.Select, "save preset"), c.keys.Back))
+	case c.exportMode == exportCopying:
+		k := c.keys
+		s.WriteString(c.styles.helpLine(navHelp(k.Up, k.Down), withDesc(k.Select, "copy"), withDesc(k.Back, "close")))
+	case c.exportMode == exportSaving:
+		s.WriteString(c.styles.helpLine(withDesc(c.keys.Execute, "save"), newBinding("change format", "tab"), withDesc(c.keys.Back, "cancel")))
+	case c.presetMode == presetsPicking:
+		k := c.keys
+		s.WriteString(c.styles.helpLine(navHelp(k.Up, k.Down), withDesc(k.Select, "use preset"),
+			k.PresetSave, k.PresetDelete, withDesc(k.Back, "close")))
+	case c.sendMode == sendPickingField:
+		k := c.keys
+		s.WriteString(c.styles.helpLine(navHelp(k.Up, k.Down), withDesc(k.Select, "pick field"), withDesc(k.Back, "close")))
+	case c.sendMode == sendPickingFeature:
+		k := c.keys
+		s.WriteString(c.styles.helpLine(navHelp(k.Up, k.Down), withDesc(k.Select, "send and run"), k.Back))
+	case c.loadingFile:
+		s.WriteString(c.styles.helpLine(withDesc(c.keys.Execute, "load"), withDesc(c.keys.Back, "cancel")))
+	case c.outputSearching:
+		s.WriteString(c.styles.help.Render("type to search • ") +
+			c.styles.helpLine(withDesc(c.keys.Select, "find"), withDesc(c.keys.Back, "cancel")))
+	case c.outputFocused:
+		k := c.keys
+		page := newBinding("page", firstKeyLabel(k.PageUp)+"/"+firstKeyLabel(k.PageDown))
+		s.WriteString(c.styles.helpLine(withDesc(navHelp(k.Up, k.Down), "scroll"), page, newBinding("top/bottom", "G/SHIFT+G"),
+			withDesc(k.Filter, "search"), newBinding("next/previous match", "N/SHIFT+N"), withDesc(k.Back, "back to input")))
+	case c.completing():
+		k := c.keys
+		s.WriteString(c.styles.helpLine(navHelp(k.HistoryPrev, k.HistoryNext), k.Complete, k.Execute, withDesc(k.Back, "dismiss")))
+	default:
+		k := c.keys
+		execute := k.Execute
+		if c.multiline() {
+			execute = k.ExecuteMultiline
+		}
+		bindings := []key.Binding{execute, navHelp(k.HistoryPrev, k.HistoryNext), k.HistorySearch, k.Presets, k.LoadFile,
+			k.OutputFormat, k.FocusOutput, k.Copy, k.Save, k.Send, k.Compare}
+		bindings = append(bindings, c.tabHelp()...)
+		s.WriteString(c.styles.helpLine(append(bindings, k.FeatureHelp, k.Undo, k.Redo, k.Back)...))
+	}
+
+	// Wrap long help lines so the layout knows their real height
+	if c.width > 0 {
+		return lipgloss.NewStyle().MaxWidth(c.width).Width(c.width).Render(s.String())
+	}
 	return s.String()
 }
@@ -0,0 +1,627 @@
+package main
+
+import (
+	"bhelper/batch"
+	"bhelper/feature"
+	"bhelper/recording"
+	"bhelper/server"
+	"bhelper/store"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usageText = `Usage:
+  bhelper [--config <path>] [--record <file>] <command>
+
+Commands:
+  bhelper                          start the interactive interface
+  bhelper run <feature-id> [input] run a feature (reads stdin when input is omitted or "-")
+      -o, --output text|json|yaml|csv   output format (default text)
+      -p, --preset <name>               use a saved preset as input
+      --timeout <duration>              abort the run after this long (e.g. 10s)
+  bhelper run '<id>[.output] [input] | <id>[.output] [input with {}] ...'
+                                   run a pipeline: each feature gets an output of the
+                                   previous one as input, or in place of {}
+  bhelper batch <feature-id> [file]
+                                   run a feature on every line of a file (or stdin),
+                                   printing one result per line in input order
+      -o, --output jsonl|csv            output format (default jsonl)
+      -j, --jobs <n>                    number of inputs run at once (default CPU count)
+      --timeout <duration>              abort each input after this long
+  bhelper serve                    serve the features over a local HTTP JSON API
+      --addr <host:port>                listen address (default 127.0.0.1:8080)
+      --max-body <bytes>                largest accepted request body (default 1 MiB)
+  bhelper replay <file>            run the feature runs of a session log again and report
+                                   the outputs that changed (reads stdin for "-"). Fields
+                                   that change on every run, such as the current time or
+                                   simulated figures, are not compared
+      --ignore <key,...>                field keys not compared either
+      --timeout <duration>              abort each run after this long
+  bhelper list                     list registered features
+  bhelper presets [feature-id]     list saved presets
+  bhelper stats                    show how often each feature was used
+  bhelper complete <feature-id> [input]
+                                   print the completions of an input, one
+                                   "completed input<TAB>description" per line
+  bhelper completion bash|zsh|fish print a shell completion script, e.g.
+                                   source <(bhelper completion bash)
+  bhelper help [feature-id]        show usage or help for a feature
+
+Global flags:
+  --config <path>       configuration file
+  --record <file>       append feature runs to a session log for 'bhelper replay'
+                        (in the interactive interface, the runs started with Enter)
+
+Environment:
+  BHELPER_CONFIG        configuration file (default $XDG_CONFIG_HOME/bhelper/config.yaml)
+  BHELPER_TIMEOUT       timeouts, e.g. "30s" for every feature or "collision=2m,time=1s"
+  BHELPER_HISTORY_SIZE  number of inputs kept per feature in the TUI history
+`
+
+// errUsage marks errors caused by invalid command-line usage
+var errUsage = errors.New("invalid usage")
+
+// commandRunner executes non-interactive subcommands against the feature registry
+type commandRunner struct {
+	registry *feature.FeatureRegistry
+	timeouts timeoutPolicy
+	presets  *store.Presets
+	usage    *store.Usage
+	recorder *recording.Recorder
+	order    feature.SortOrder
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+}
+
+// execute runs a non-interactive subcommand and returns the process exit code
+func (r *commandRunner) execute(args []string) int {
+	var err error
+	switch args[0] {
+	case "run":
+		err = r.run(args[1:])
+	case "batch":
+		err = r.batch(args[1:])
+	case "serve":
+		err = r.serve(args[1:])
+	case "list":
+		err = r.list()
+	case "stats":
+		err = r.stats()
+	case "presets":
+		err = r.listPresets(args[1:])
+	case "replay":
+		err = r.replay(args[1:])
+	case "complete":
+		err = r.complete(args[1:])
+	case "completion":
+		err = r.completionScript(args[1:])
+	case "__complete":
+		err = r.shellComplete(args[1:])
+	case "help", "-h", "--help":
+		err = r.help(args[1:])
+	default:
+		err = fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+
+	if err == nil {
+		return exitOK
+	}
+
+	fmt.Fprintf(r.stderr, "Error: %v\n", err)
+	if ie, ok := feature.AsInputError(err); ok {
+		line, marker := ie.Context()
+		fmt.Fprintf(r.stderr, "  %s\n  %s\n", line, marker)
+		if ie.Suggestion != "" {
+			fmt.Fprintf(r.stderr, "Hint: %s\n", ie.Suggestion)
+		}
+	}
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(r.stderr, "\n"+usageText)
+		return exitUsage
+	}
+	return exitError
+}
+
+// run executes a single feature and prints its result
+func (r *commandRunner) run(args []string) error {
+	fs := r.newFlagSet("run")
+	output := fs.String("output", string(feature.FormatText), "output format")
+	fs.StringVar(output, "o", string(feature.FormatText), "output format")
+	timeout := fs.Duration("timeout", 0, "execution timeout")
+	preset := fs.String("preset", "", "preset name")
+	fs.StringVar(preset, "p", "", "preset name")
+
+	args, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("%w: run requires a feature ID", errUsage)
+	}
+
+	format, err := feature.ParseFormat(*output)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if r.isPipeline(args) {
+		if *preset != "" {
+			return fmt.Errorf("%w: --preset cannot be used with a pipeline", errUsage)
+		}
+		return r.runPipeline(strings.Join(args, " "), format, *timeout)
+	}
+
+	f, err := r.lookup(args[0])
+	if err != nil {
+		return err
+	}
+
+	var input string
+	if *preset != "" {
+		input, err = r.presetInput(f.ID(), *preset, args[1:])
+	} else {
+		input, err = r.readInput(args[1:])
+	}
+	if err != nil {
+		return err
+	}
+
+	d := r.timeouts.For(f)
+	if *timeout > 0 {
+		d = *timeout
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	r.recordUsage(f.ID())
+	result, err := runFeature(ctx, f, input, d, nil)
+	r.recordRun(f.ID(), input, result, err)
+	if err != nil {
+		return err
+	}
+
+	return r.writeDocument(feature.NewDocument(f.ID(), input, result), format)
+}
+
+// isPipeline reports whether run arguments describe a pipeline rather than
+// a feature ID followed by its input. A pipeline is given as a single quoted
+// specification, so a feature ID followed by an input holding "|" runs that
+// feature.
+func (r *commandRunner) isPipeline(args []string) bool {
+	if _, ok := r.registry.Get(args[0]); ok {
+		return false
+	}
+	return strings.ContainsAny(args[0], "|. ")
+}
+
+// runPipeline runs features in sequence, feeding each one an output field
+// of the previous result, and prints the last result. A field picked on
+// the last stage is printed on its own.
+func (r *commandRunner) runPipeline(spec string, format feature.Format, timeout time.Duration) error {
+	stages, err := feature.ParsePipeline(spec)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	// Resolve every feature before running the first one
+	features := make([]feature.Feature, len(stages))
+	for i, stage := range stages {
+		if features[i], err = r.lookup(stage.FeatureID); err != nil {
+			return err
+		}
+	}
+
+	input := stages[0].Input
+	if input == "" {
+		if input, err = r.readInput(nil); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var result *feature.Result
+	for i, f := range features {
+		if i > 0 {
+			value, err := feature.SelectOutput(features[i-1], result, stages[i-1].Field)
+			if err != nil {
+				return err
+			}
+			input = stages[i].PipedInput(value)
+		}
+
+		d := r.timeouts.For(f)
+		if timeout > 0 {
+			d = timeout
+		}
+		// Stages after a failing one never run and are not counted
+		r.recordUsage(f.ID())
+		result, err = runFeature(ctx, f, input, d, nil)
+		r.recordRun(f.ID(), input, result, err)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.ID(), err)
+		}
+	}
+
+	last := len(stages) - 1
+	if stages[last].Field != "" {
+		value, err := feature.SelectOutput(features[last], result, stages[last].Field)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(r.stdout, value)
+		return err
+	}
+	return r.writeDocument(feature.NewDocument(features[last].ID(), input, result), format)
+}
+
+// batch runs a feature on every line of its input concurrently and prints
+// the results in input order. Failed lines are reported in the output and
+// do not stop the others.
+func (r *commandRunner) batch(args []string) error {
+	fs := r.newFlagSet("batch")
+	output := fs.String("output", string(batch.FormatJSONL), "output format")
+	fs.StringVar(output, "o", string(batch.FormatJSONL), "output format")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "concurrent inputs")
+	fs.IntVar(jobs, "j", runtime.NumCPU(), "concurrent inputs")
+	timeout := fs.Duration("timeout", 0, "execution timeout per input")
+
+	args, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("%w: batch requires a feature ID and at most one input file", errUsage)
+	}
+	if *jobs < 1 {
+		return fmt.Errorf("%w: --jobs must be at least 1", errUsage)
+	}
+
+	format, err := batch.ParseFormat(*output)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	f, err := r.lookup(args[0])
+	if err != nil {
+		return err
+	}
+
+	in := r.stdin
+	if len(args) == 2 && args[1] != "-" {
+		file, err := os.Open(args[1])
+		if err != nil {
+			return fmt.Errorf("failed to open input: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	d := r.timeouts.For(f)
+	if *timeout > 0 {
+		d = *timeout
+	}
+
+	w, err := batch.NewWriter(r.stdout, format, f.ID())
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	r.recordUsage(f.ID())
+	total, failed := 0, 0
+	warned := feature.Cancellable(f)
+	err = batch.Run(ctx, in, *jobs, func(ctx context.Context, input string) (*feature.Result, error) {
+		return runFeature(ctx, f, input, d, nil)
+	}, func(o batch.Outcome) error {
+		r.recordRun(f.ID(), o.Input, o.Result, o.Err)
+		total++
+		if o.Err != nil {
+			failed++
+		}
+		// Timed out runs of other features hold a job's worth of CPU
+		if !warned && errors.Is(o.Err, errTimedOut) {
+			warned = true
+			fmt.Fprintf(r.stderr, "Warning: %s cannot be cancelled, inputs that timed out keep running in the background\n", f.ID())
+		}
+		return w.Write(o)
+	})
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d inputs failed", failed, total)
+	}
+	return nil
+}
+
+// serve exposes the registry over HTTP until interrupted
+func (r *commandRunner) serve(args []string) error {
+	fs := r.newFlagSet("serve")
+	addr := fs.String("addr", server.DefaultAddr, "listen address")
+	maxBody := fs.Int64("max-body", server.DefaultMaxBodySize, "largest request body in bytes")
+
+	args, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("%w: serve takes no arguments", errUsage)
+	}
+	if *maxBody < 1 {
+		return fmt.Errorf("%w: --max-body must be positive", errUsage)
+	}
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	s := server.New(r.registry, r.timeouts.For)
+	s.SetMaxBodySize(*maxBody)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(r.stderr, "Serving %d features on http://%s (Ctrl+C to stop)\n", len(r.registry.List()), l.Addr())
+	var uncancellable []string
+	for _, f := range r.registry.List() {
+		if !feature.Cancellable(f) {
+			uncancellable = append(uncancellable, f.ID())
+		}
+	}
+	if len(uncancellable) > 0 {
+		fmt.Fprintf(r.stderr, "Warning: %s cannot be cancelled, requests that time out keep running in the background\n", strings.Join(uncancellable, ", "))
+	}
+	return s.Serve(ctx, l)
+}
+
+// recordUsage counts a run of a feature. Statistics that cannot be saved
+// are reported without failing the command.
+func (r *commandRunner) recordUsage(id string) {
+	if r.usage == nil {
+		return
+	}
+
+	r.usage.Record(id, time.Now())
+	if err := r.usage.Save(); err != nil {
+		fmt.Fprintf(r.stderr, "Warning: %v\n", err)
+	}
+}
+
+// recordRun adds a run to the session log given with --record. Runs that
+// cannot be logged are reported without failing the command.
+func (r *commandRunner) recordRun(featureID, input string, result *feature.Result, err error) {
+	if r.recorder == nil {
+		return
+	}
+
+	if err := r.recorder.Record(recording.NewEntry(time.Now(), featureID, input, result, err)); err != nil {
+		fmt.Fprintf(r.stderr, "Warning: %v\n", err)
+	}
+}
+
+// stats prints the usage statistics of every registered feature, most
+// used first
+func (r *commandRunner) stats() error {
+	if r.usage == nil {
+		fmt.Fprintln(r.stdout, "Usage statistics are disabled (track_usage in the config file)")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(r.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FEATURE\tRUNS\tLAST USED")
+	for _, f := range r.registry.Sorted(feature.SortMostUsed, r.usage.Count) {
+		u := r.usage.Get(f.ID())
+		last := "never"
+		if u.Count > 0 {
+			last = u.LastUsed.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", f.ID(), u.Count, last)
+	}
+	return w.Flush()
+}
+
+// list prints every registered feature in the configured order
+func (r *commandRunner) list() error {
+	w := tabwriter.NewWriter(r.stdout, 0, 0, 2, ' ', 0)
+	for _, f := range r.registry.Sorted(r.order, usageCounts(r.usage)) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", f.ID(), f.Name(), f.Description())
+	}
+	return w.Flush()
+}
+
+// listPresets prints the saved presets of one or all features
+func (r *commandRunner) listPresets(args []string) error {
+	ids := make([]string, 0)
+	if len(args) > 0 {
+		f, err := r.lookup(args[0])
+		if err != nil {
+			return err
+		}
+		ids = append(ids, f.ID())
+	} else {
+		for _, f := range r.registry.List() {
+			ids = append(ids, f.ID())
+		}
+	}
+
+	w := tabwriter.NewWriter(r.stdout, 0, 0, 2, ' ', 0)
+	for _, id := range ids {
+		for _, p := range r.presets.List(id) {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", id, p.Name, p.Input)
+		}
+	}
+	return w.Flush()
+}
+
+// complete prints the completions of the end of an input, each as the
+// whole completed input followed by a tab and the description, if any
+func (r *commandRunner) complete(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: complete requires a feature ID", errUsage)
+	}
+
+	f, err := r.lookup(args[0])
+	if err != nil {
+		return err
+	}
+
+	input := strings.Join(args[1:], " ")
+	completions := feature.Complete(f, input, len(input))
+	for i, candidate := range completions.Candidates {
+		line, _ := completions.Apply(input, i)
+		if candidate.Description != "" {
+			line += "\t" + candidate.Description
+		}
+		fmt.Fprintln(r.stdout, line)
+	}
+	return nil
+}
+
+// presetInput resolves the input saved under a preset name
+func (r *commandRunner) presetInput(featureID, name string, args []string) (string, error) {
+	if len(args) > 0 {
+		return "", fmt.Errorf("%w: --preset cannot be combined with an input", errUsage)
+	}
+
+	p, ok := r.presets.Find(featureID, name)
+	if !ok {
+		return "", fmt.Errorf("unknown preset %q for %s (see 'bhelper presets %s')", name, featureID, featureID)
+	}
+	return p.Input, nil
+}
+
+// help prints general usage, or the detailed help of a single feature
+func (r *commandRunner) help(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(r.stdout, usageText)
+		return nil
+	}
+
+	f, err := r.lookup(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(r.stdout, "%s (%s)\n%s\n\n", f.Name(), f.ID(), f.Description())
+	fmt.Fprintf(r.stdout, "%s\n", strings.TrimRight(f.Help(), "\n"))
+
+	examples := f.Examples()
+	if len(examples) > 0 {
+		fmt.Fprint(r.stdout, "\nExamples:\n")
+		for _, ex := range examples {
+			fmt.Fprintf(r.stdout, "  bhelper run %s %q\n      %s\n", f.ID(), ex.Input, ex.Description)
+		}
+	}
+
+	if outputs := feature.OutputsOf(f); len(outputs) > 0 {
+		fmt.Fprintf(r.stdout, "\nOutputs (pick one in a pipeline with %s.<output>, the first is the default):\n", f.ID())
+		for _, out := range outputs {
+			fmt.Fprintf(r.stdout, "  %s\n      %s\n", out.Key, out.Description)
+		}
+	}
+
+	if c, ok := f.(feature.Configurable); ok {
+		fmt.Fprintf(r.stdout, "\nOptions (features.%s.options in the config file):\n", f.ID())
+		for _, spec := range c.OptionSpecs() {
+			fmt.Fprintf(r.stdout, "  %s (%s, default %v)\n      %s\n", spec.Key, spec.Type, spec.Default, spec.Description)
+		}
+	}
+	return nil
+}
+
+// lookup finds a feature by ID
+func (r *commandRunner) lookup(id string) (feature.Feature, error) {
+	f, ok := r.registry.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown feature %q (see 'bhelper list')", id)
+	}
+	return f, nil
+}
+
+// readInput joins the remaining arguments, falling back to stdin when none
+// are given or the single argument is "-"
+func (r *commandRunner) readInput(args []string) (string, error) {
+	if len(args) > 0 && !(len(args) == 1 && args[0] == "-") {
+		return strings.Join(args, " "), nil
+	}
+
+	data, err := io.ReadAll(r.stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// writeDocument prints a result document in the given format
+func (r *commandRunner) writeDocument(doc *feature.Document, format feature.Format) error {
+	var b strings.Builder
+	if err := feature.Encode(&b, doc, format); err != nil {
+		return err
+	}
+
+	out := b.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(r.stdout, out)
+	return err
+}
+
+// newFlagSet creates a flag set whose parse errors are reported as usage errors
+func (r *commandRunner) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseInterspersed parses flags that may appear anywhere among the
+// positional arguments, stopping at "--"
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		// flag.Parse consumes a "--" terminator, everything after it is positional
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bhelper/feature"
+	timeconverter "bhelper/feature/time"
+	"bhelper/store"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRunner returns a runner over the character and time features
+// reading stdin from the given text
+func newTestRunner(t *testing.T, stdin string) (*commandRunner, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	registry := feature.NewFeatureRegistry()
+	for _, f := range []feature.Feature{feature.NewCharacterAnalyzer(), timeconverter.NewTimeConverter()} {
+		if err := registry.Register(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	r := &commandRunner{
+		registry: registry,
+		stdin:    strings.NewReader(stdin),
+		stdout:   &stdout,
+		stderr:   &stderr,
+	}
+	return r, &stdout, &stderr
+}
+
+func TestRunPipeline(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+	}{
+		{"literal bar", []string{"run", "-o", "json", "character", "|"}, "|"},
+		{"bar within input", []string{"run", "-o", "json", "character", "a", "|", "b"}, "a | b"},
+		{"quoted pipeline", []string{"run", "-o", "json", "time 90s | character"}, "90"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, stdout, stderr := newTestRunner(t, "")
+			if code := r.execute(tt.args); code != exitOK {
+				t.Fatalf("Expected exit 0, got %d: %s", code, stderr)
+			}
+
+			var doc feature.Document
+			if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil {
+				t.Fatalf("Expected a JSON document, got %q", stdout)
+			}
+			if doc.Feature != "character" || doc.Input != tt.input {
+				t.Errorf("Expected character run on %q, got %s on %q", tt.input, doc.Feature, doc.Input)
+			}
+		})
+	}
+}
+
+func TestBatchWarnsAboutAbandonedRuns(t *testing.T) {
+	tests := []struct {
+		name string
+		f    feature.Feature
+		warn bool
+	}{
+		{"uncancellable", stub{id: "slow", delay: 200 * time.Millisecond}, true},
+		{"fast", stub{id: "slow"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _, stderr := newTestRunner(t, "a\nb\n")
+			r.registry.Register(tt.f)
+
+			r.execute([]string{"batch", "--timeout", "20ms", "slow"})
+			warnings := strings.Count(stderr.String(), "Warning: slow cannot be cancelled")
+			if tt.warn && warnings != 1 {
+				t.Errorf("Expected a single warning, got:\n%s", stderr)
+			}
+			if !tt.warn && warnings != 0 {
+				t.Errorf("Expected no warning, got:\n%s", stderr)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{name: "run", args: []string{"run", "time", "90s"}, stdout: "Time Conversions (90s)"},
+		{name: "input words", args: []string{"run", "time", "1", "min"}, stdout: "Time Conversions (1 min)"},
+		{name: "stdin", args: []string{"run", "time"}, stdin: "90s\n", stdout: "Time Conversions (90s)"},
+		{name: "dash reads stdin", args: []string{"run", "time", "-"}, stdin: "2m\r\n", stdout: "Time Conversions (2m)"},
+		{name: "dash within input", args: []string{"run", "-o", "csv", "character", "-", "x"}, stdout: "character,- x,"},
+		{name: "flags after arguments", args: []string{"run", "time", "90s", "-o", "yaml"}, stdout: "feature: time\n"},
+		{name: "terminated flags", args: []string{"run", "-o", "csv", "character", "--", "-o"}, stdout: "character,-o,"},
+		{name: "preset", args: []string{"run", "-p", "short", "time"}, stdout: "Time Conversions (90s)"},
+		{name: "preset with input", args: []string{"run", "--preset", "short", "time", "5s"}, code: exitUsage, stderr: "--preset cannot be combined with an input"},
+		{name: "unknown preset", args: []string{"run", "-p", "long", "time"}, code: exitError, stderr: `unknown preset "long" for time`},
+		{name: "missing feature", args: []string{"run"}, code: exitUsage, stderr: "run requires a feature ID"},
+		{name: "unknown format", args: []string{"run", "-o", "xml", "time", "1s"}, code: exitUsage, stderr: "unknown output format"},
+		{name: "unknown flag", args: []string{"run", "--verbose", "time", "1s"}, code: exitUsage, stderr: "flag provided but not defined: -verbose"},
+		{name: "unknown feature", args: []string{"run", "weather", "today"}, code: exitError, stderr: `unknown feature "weather" (see 'bhelper list')`},
+		{name: "invalid input", args: []string{"run", "time", "90x"}, code: exitError, stderr: "  90x\n"},
+		{name: "list", args: []string{"list"}, stdout: "time       Time Converter"},
+		{name: "help", args: []string{"help"}, stdout: "Usage:\n"},
+		{name: "help flag", args: []string{"--help"}, stdout: "Usage:\n"},
+		{name: "feature help", args: []string{"help", "time"}, stdout: "bhelper run time \"100ms\""},
+		{name: "unknown feature help", args: []string{"help", "weather"}, code: exitError, stderr: `unknown feature "weather"`},
+		{name: "presets", args: []string{"presets", "time"}, stdout: "time  short  90s\n"},
+		{name: "stats disabled", args: []string{"stats"}, stdout: "Usage statistics are disabled"},
+		{name: "completion", args: []string{"completion", "fish"}, stdout: "complete -c bhelper"},
+		{name: "unsupported shell", args: []string{"completion", "tcsh"}, code: exitUsage, stderr: `unsupported shell "tcsh"`},
+		{name: "unknown command", args: []string{"launch"}, code: exitUsage, stderr: `unknown command "launch"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, stdout, stderr := newTestRunner(t, tt.stdin)
+			r.presets = store.NewPresets("")
+			if err := r.presets.Add("time", "short", "90s"); err != nil {
+				t.Fatal(err)
+			}
+
+			if code := r.execute(tt.args); code != tt.code {
+				t.Fatalf("Expected exit %d, got %d: %s", tt.code, code, stderr)
+			}
+			if !strings.Contains(stdout.String(), tt.stdout) {
+				t.Errorf("Expected output containing %q, got:\n%s", tt.stdout, stdout)
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("Expected errors containing %q, got:\n%s", tt.stderr, stderr)
+			}
+
+			switch tt.code {
+			case exitOK:
+				if stderr.Len() > 0 {
+					t.Errorf("Expected no errors, got:\n%s", stderr)
+				}
+			case exitUsage:
+				if !strings.HasSuffix(stderr.String(), usageText) {
+					t.Errorf("Expected the usage after a usage error, got:\n%s", stderr)
+				}
+			case exitError:
+				if strings.Contains(stderr.String(), usageText) || stdout.Len() > 0 {
+					t.Errorf("Expected only the error, got:\n%s%s", stdout, stderr)
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineCountsStagesThatRan(t *testing.T) {
+	tests := []struct {
+		spec  string
+		code  int
+		count map[string]int
+	}{
+		{"time 90s | character", exitOK, map[string]int{"time": 1, "character": 1}},
+		{"time 90x | character", exitError, map[string]int{"time": 1, "character": 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			r, _, stderr := newTestRunner(t, "")
+			r.usage = store.NewUsage("")
+
+			if code := r.execute([]string{"run", tt.spec}); code != tt.code {
+				t.Fatalf("Expected exit %d, got %d: %s", tt.code, code, stderr)
+			}
+			for id, want := range tt.count {
+				if got := r.usage.Count(id); got != want {
+					t.Errorf("Expected %d runs of %s, got %d", want, id, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bhelper/feature"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// comparePaneGap is the space between the two panes
+	comparePaneGap = 1
+
+	// defaultComparePaneWidth is used before the window size is known
+	defaultComparePaneWidth = 40
+)
+
+// compareLabels name the two sides of the compare mode
+var compareLabels = [2]string{"A: ", "B: "}
+
+// comparePane is one side of the compare mode: an input and its result
+type comparePane struct {
+	input   textinput.Model
+	result  *feature.Result
+	err     error
+	runID   int
+	running bool
+	cancel  context.CancelFunc
+}
+
+// openCompare runs the selected feature on two inputs side by side, both
+// starting from the current input and its result. The inputs take a single
+// line, line breaks of a multi-line input become spaces.
+func (c CLI) openCompare() (CLI, tea.Cmd) {
+	input := c.inputValue()
+	c.cancelExecution()
+	c.blurInput()
+
+	for i := range c.compare {
+		ti := textinput.New()
+		ti.Placeholder = "Type your input..."
+		ti.Width = c.compareInputWidth()
+		ti.SetValue(input)
+		c.compare[i] = comparePane{input: ti}
+		if c.result != nil && c.resultInput == input {
+			c.compare[i].result = c.result
+		}
+	}
+
+	c.mode = ModeCompare
+	c.compareFocus = 1
+	c.compareOffset = 0
+	return c, c.compare[1].input.Focus()
+}
+
+// closeCompare cancels the runs of both panes and returns to the feature
+func (c CLI) closeCompare() (CLI, tea.Cmd) {
+	c.cancelCompare()
+	c.compare[c.compareFocus].input.Blur()
+	c.mode = ModeFeatureExecute
+	return c, c.focusInput()
+}
+
+// cancelCompare stops the runs of both panes and drops their results
+func (c *CLI) cancelCompare() {
+	for i := range c.compare {
+		p := &c.compare[i]
+		if p.cancel != nil {
+			p.cancel()
+			p.cancel = nil
+		}
+		p.running = false
+		p.runID = 0
+	}
+}
+
+// compareRunning reports whether either pane is still running
+func (c CLI) compareRunning() bool {
+	return c.compare[0].running || c.compare[1].running
+}
+
+// updateCompare handles keys in the compare mode
+func (c CLI) updateCompare(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c.status = ""
+
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit), key.Matches(msg, c.keys.Back):
+		if c.compareRunning() {
+			c.cancelCompare()
+			c.status = "Cancelled"
+			return c, nil
+		}
+		if key.Matches(msg, c.keys.ForceQuit) {
+			return c, tea.Quit
+		}
+		return c.closeCompare()
+
+	case key.Matches(msg, c.keys.Execute):
+		return c.runCompare()
+
+	case key.Matches(msg, c.keys.FocusOutput), msg.Type == tea.KeyShiftTab:
+		c.compare[c.compareFocus].input.Blur()
+		c.compareFocus = 1 - c.compareFocus
+		return c, c.compare[c.compareFocus].input.Focus()
+
+	case key.Matches(msg, c.keys.PageUp):
+		c.compareOffset = max(c.compareOffset-c.comparePaneHeight(), 0)
+		return c, nil
+
+	case key.Matches(msg, c.keys.PageDown):
+		c.compareOffset = min(c.compareOffset+c.comparePaneHeight(), c.compareMaxOffset())
+		return c, nil
+	}
+
+	p := &c.compare[c.compareFocus]
+	oldValue := p.input.Value()
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() == oldValue {
+		return c, cmd
+	}
+
+	// Like the execute view, live features re-run and others drop the
+	// result that no longer matches the input
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+	p.running = false
+	p.runID = 0
+	p.result, p.err = nil, nil
+	if !feature.IsLive(c.selectedFeature) {
+		return c, cmd
+	}
+
+	c.editSeq++
+	return c, tea.Batch(cmd, liveTick(c.liveDebounce, c.editSeq))
+}
+
+// runCompare runs the feature on both inputs at once. Invalid inputs are
+// reported in their pane without running.
+func (c CLI) runCompare() (CLI, tea.Cmd) {
+	c.cancelCompare()
+	c.compareOffset = 0
+
+	var cmds []tea.Cmd
+	timeout := c.timeouts.For(c.selectedFeature)
+	for i := range c.compare {
+		p := &c.compare[i]
+		p.result, p.err = nil, nil
+
+		input := p.input.Value()
+		if input == "" {
+			continue
+		}
+		if err := feature.Validate(c.selectedFeature, input); err != nil {
+			p.err = err
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		progress := make(chan float64, 1)
+		p.runID = c.nextRunID()
+		p.running = true
+		p.cancel = cancel
+		cmds = append(cmds,
+			executeCmd(ctx, c.selectedFeature, input, p.runID, timeout, progress),
+			listenProgress(p.runID, progress))
+	}
+
+	if len(cmds) > 0 {
+		cmds = append(cmds, c.spinner.Tick)
+	}
+	return c, tea.Batch(cmds...)
+}
+
+// handleCompareResult stores a result in the pane that started the run
+func (c CLI) handleCompareResult(msg resultMsg) (CLI, tea.Cmd) {
+	for i := range c.compare {
+		p := &c.compare[i]
+		if p.running && p.runID == msg.runID {
+			p.running = false
+			p.cancel = nil
+			p.result, p.err = msg.result, msg.err
+		}
+	}
+	return c, nil
+}
+
+// comparePaneWidth returns the outer width of each pane
+func (c CLI) comparePaneWidth() int {
+	if c.width == 0 {
+		return defaultComparePaneWidth
+	}
+	return max((c.width-comparePaneGap)/2, minOutputWidth+outputFrameWidth)
+}
+
+// compareInputWidth returns the width of the text inputs, leaving room for
+// the label and the prompt
+func (c CLI) compareInputWidth() int {
+	return max(c.comparePaneWidth()-lipgloss.Width(compareLabels[0])-lipgloss.Width(c.textInput.Prompt)-1, minOutputWidth)
+}
+
+// comparePaneHeight returns the number of result lines each pane shows
+func (c CLI) comparePaneHeight() int {
+	if c.height == 0 {
+		return 0
+	}
+	used := lipgloss.Height(c.renderCompareHeader()) + lipgloss.Height(c.renderCompareFooter()) + outputFrameHeight + 1
+	return max(c.height-used, minOutputHeight)
+}
+
+// compareMaxOffset returns how far the panes can scroll down
+func (c CLI) compareMaxOffset() int {
+	height := c.comparePaneHeight()
+	if height == 0 {
+		return 0
+	}
+
+	lines := 0
+	for i := range c.compare {
+		lines = max(lines, lipgloss.Height(c.renderComparePane(i, c.comparePaneWidth()-outputFrameWidth)))
+	}
+	return max(lines-height, 0)
+}
+
+// compareSummary counts the fields whose values differ between the panes
+func (c CLI) compareSummary() string {
+	a, b := c.compare[0].result, c.compare[1].result
+	if a == nil || b == nil || c.compareRunning() {
+		return ""
+	}
+
+	changes := len(feature.DiffFields(a, b))
+	total := len(a.Fields())
+	switch {
+	case total == 0 && changes == 0:
+		return ""
+	case changes == 0:
+		return "Results are identical"
+	case changes == 1:
+		return fmt.Sprintf("1 of %d fields differs", total)
+	}
+	return fmt.Sprintf("%d of %d fields differ", changes, total)
+}
+
+// renderCompare shows both inputs with their results side by side
+func (c CLI) renderCompare() string {
+	header := c.renderCompareHeader()
+	footer := c.renderCompareFooter()
+
+	width := c.comparePaneWidth()
+	height := c.comparePaneHeight()
+	contentWidth := width - outputFrameWidth
+
+	// Both panes scroll together so matching lines stay side by side
+	offset := min(c.compareOffset, c.compareMaxOffset())
+
+	var panes [2][]string
+	for i := range c.compare {
+		panes[i] = strings.Split(c.renderComparePane(i, contentWidth), "\n")
+	}
+
+	var boxes [2]string
+	for i := range panes {
+		visible := panes[i][min(offset, len(panes[i])):]
+		if height > 0 && len(visible) > height {
+			visible = visible[:height]
+		}
+		box := lipgloss.NewStyle().Width(contentWidth)
+		if height > 0 {
+			box = box.Height(height)
+		}
+		style := c.styles.outputBox
+		if i == c.compareFocus {
+			style = style.BorderForeground(c.styles.selectedFeature.GetForeground())
+		}
+		boxes[i] = style.Render(box.Render(strings.Join(visible, "\n")))
+	}
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, boxes[0], strings.Repeat(" ", comparePaneGap), boxes[1])
+	return header + body + "\n\n" + footer
+}
+
+// renderCompareHeader shows the title and the two inputs
+func (c CLI) renderCompareHeader() string {
+	var s strings.Builder
+	s.WriteString(c.renderTabBar())
+
+	title := c.styles.title.Render(fmt.Sprintf("⚖ Compare: %s", c.selectedFeature.Name()))
+	s.WriteString(title + "\n\n")
+
+	width := c.comparePaneWidth()
+	var inputs [2]string
+	for i, p := range c.compare {
+		line := c.styles.label.Render(compareLabels[i]) + p.input.View()
+		inputs[i] = lipgloss.NewStyle().Width(width).MaxWidth(width).Render(line)
+	}
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, inputs[0], strings.Repeat(" ", comparePaneGap), inputs[1]) + "\n\n")
+
+	if summary := c.compareSummary(); summary != "" {
+		s.WriteString(c.styles.section.Render(summary) + "\n")
+	}
+	return s.String()
+}
+
+// renderComparePane renders the result of one pane wrapped to width,
+// highlighting what differs from the other pane
+func (c CLI) renderComparePane(i, width int) string {
+	p := c.compare[i]
+	other := c.compare[1-i]
+
+	var content string
+	switch {
+	case p.running:
+		content = c.spinner.View() + " " + c.styles.help.Render("Running...")
+	case p.err != nil:
+		content = c.styles.invalid.Render("Error: " + p.err.Error())
+	case p.result == nil:
+		content = c.styles.help.Render(fmt.Sprintf("Press %s to run both inputs", firstKeyLabel(c.keys.Execute)))
+	case other.result != nil && !other.running:
+		content = c.styles.renderComparedResult(p.result, other.result)
+	default:
+		content = c.styles.renderResult(p.result)
+	}
+	return lipgloss.NewStyle().Width(width).Render(content)
+}
+
+// renderCompareFooter shows the status and help lines
+func (c CLI) renderCompareFooter() string {
+	var s strings.Builder
+	if c.status != "" {
+		s.WriteString(c.styles.status.Render(c.status) + "\n")
+	}
+
+	k := c.keys
+	s.WriteString(c.styles.helpLine(withDesc(k.Execute, "run both"), withDesc(k.FocusOutput, "switch input"),
+		withDesc(k.PageUp, "scroll up"), withDesc(k.PageDown, "scroll down"), k.Back))
+
+	if c.width > 0 {
+		return lipgloss.NewStyle().MaxWidth(c.width).Width(c.width).Render(s.String())
+	}
+	return s.String()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bhelper/feature"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxCompletionRows is the number of suggestions shown at once
+const maxCompletionRows = 6
+
+// warmCompletions gathers slow completion candidates in the background so
+// the first keystroke does not wait for them
+func warmCompletions(features []feature.Feature) tea.Cmd {
+	return func() tea.Msg {
+		for _, f := range features {
+			feature.Warm(f)
+		}
+		return nil
+	}
+}
+
+// completions returns the suggestions of the selected feature for the word
+// at the cursor. Multi-line and empty inputs are not completed.
+func (c CLI) completions() feature.Completions {
+	input := c.inputValue()
+	if c.selectedFeature == nil || c.multiline() || input == "" {
+		return feature.Completions{}
+	}
+
+	cursor := len(string([]rune(input)[:c.textInput.Position()]))
+	return feature.Complete(c.selectedFeature, input, cursor)
+}
+
+// completing reports whether suggestions are shown below the input
+func (c CLI) completing() bool {
+	return !c.hideCompletion && !c.completions().Settled(c.inputValue())
+}
+
+// selectedCompletion returns the index of the highlighted suggestion, kept
+// in range when the cursor moved to a word with fewer candidates
+func (c CLI) selectedCompletion(completions feature.Completions) int {
+	return min(c.completionIndex, len(completions.Candidates)-1)
+}
+
+// moveCompletion highlights the previous (delta < 0) or next suggestion
+func (c CLI) moveCompletion(delta int) CLI {
+	completions := c.completions()
+	index := c.selectedCompletion(completions) + delta
+	c.completionIndex = min(max(index, 0), len(completions.Candidates)-1)
+	return c
+}
+
+// acceptCompletion replaces the word at the cursor with the highlighted
+// suggestion and moves the cursor after it
+func (c CLI) acceptCompletion() (CLI, tea.Cmd) {
+	completions := c.completions()
+	value, cursor := completions.Apply(c.inputValue(), c.selectedCompletion(completions))
+
+	c, cmd := c.replaceInput(value)
+	c.textInput.SetCursor(utf8.RuneCountInString(value[:cursor]))
+	return c, cmd
+}
+
+// renderCompletion shows the suggestions below the input, lined up with
+// the word they complete while the input is not scrolled sideways
+func (c CLI) renderCompletion() string {
+	if !c.completing() {
+		return ""
+	}
+
+	completions := c.completions()
+	candidates := completions.Candidates
+	index := c.selectedCompletion(completions)
+
+	indent := lipgloss.Width(c.styles.label.Render("Input: ")) + lipgloss.Width(c.textInput.Prompt)
+	input := c.inputValue()
+	if lipgloss.Width(input) < c.textInput.Width {
+		indent += lipgloss.Width(input[:completions.Start])
+	}
+	// The cursor column of each row sits left of the word
+	pad := strings.Repeat(" ", max(indent-2, 0))
+
+	first := max(index-maxCompletionRows+1, 0)
+	last := min(first+maxCompletionRows, len(candidates))
+
+	width := 0
+	for _, candidate := range candidates[first:last] {
+		width = max(width, lipgloss.Width(candidate.Value))
+	}
+
+	var s strings.Builder
+	for i := first; i < last; i++ {
+		cursor := "  "
+		style := c.styles.feature
+		if i == index {
+			cursor = "→ "
+			style = c.styles.selectedFeature
+		}
+
+		value := candidates[i].Value
+		s.WriteString("\n" + pad + style.Render(cursor+value))
+		if candidates[i].Description != "" {
+			gap := strings.Repeat(" ", width-lipgloss.Width(value)+2)
+			s.WriteString(c.styles.help.Render(gap + candidates[i].Description))
+		}
+	}
+	if len(candidates) > maxCompletionRows {
+		s.WriteString("\n" + pad + c.styles.help.Render(fmt.Sprintf("  %d of %d", index+1, len(candidates))))
+	}
+	return s.String()
+}
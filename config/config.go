@@ -0,0 +1,198 @@
+package config
+
+import (
+	"bhelper/feature"
+	"bhelper/store"
+	"bhelper/theme"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the name of the configuration file in the config directory
+const File = "config.yaml"
+
+// Config is the user configuration of bhelper. TrackUsage records local
+// usage statistics of features.
+type Config struct {
+	UI         UIConfig                 `yaml:"ui"`
+	Timeout    Duration                 `yaml:"timeout,omitempty"`
+	Keys       map[string][]string      `yaml:"keys,omitempty"`
+	Themes     map[string]theme.Theme   `yaml:"themes,omitempty"`
+	Features   map[string]FeatureConfig `yaml:"features,omitempty"`
+	Plugins    PluginsConfig            `yaml:"plugins,omitempty"`
+	TrackUsage bool                     `yaml:"track_usage"`
+}
+
+// PluginsConfig locates external feature executables. An empty Dir uses
+// the plugins directory in the config directory.
+type PluginsConfig struct {
+	Dir      string `yaml:"dir,omitempty"`
+	Disabled bool   `yaml:"disabled,omitempty"`
+}
+
+// UIConfig holds global settings of the interactive interface. An
+// InputWidth of 0 lets the input follow the window width, otherwise it caps
+// it. FeatureOrder also applies to 'bhelper list'. RestoreTabs reopens the
+// workspace tabs of the last session on launch.
+type UIConfig struct {
+	UndoSize     int      `yaml:"undo_size"`
+	HistorySize  int      `yaml:"history_size"`
+	InputWidth   int      `yaml:"input_width"`
+	LiveDebounce Duration `yaml:"live_debounce"`
+	Theme        string   `yaml:"theme"`
+	FeatureOrder string   `yaml:"feature_order"`
+	RestoreTabs  bool     `yaml:"restore_tabs"`
+}
+
+// FeatureConfig holds settings of a single feature. Options are passed to
+// the feature itself, the other fields are applied by the CLI. A disabled
+// feature is removed from the registry.
+type FeatureConfig struct {
+	Disabled bool           `yaml:"disabled,omitempty"`
+	Timeout  Duration       `yaml:"timeout,omitempty"`
+	Options  map[string]any `yaml:"options,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "1m30s"
+type Duration time.Duration
+
+// UnmarshalYAML parses a duration string
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return fmt.Errorf("line %d: expected a duration such as \"30s\"", node.Line)
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q", node.Line, s)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalYAML writes the duration as a string
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		UI: UIConfig{
+			UndoSize:     50,
+			HistorySize:  store.DefaultHistorySize,
+			InputWidth:   0,
+			LiveDebounce: Duration(150 * time.Millisecond),
+			Theme:        theme.Auto,
+			FeatureOrder: string(feature.SortRegistration),
+			RestoreTabs:  true,
+		},
+		Keys:       make(map[string][]string),
+		Themes:     make(map[string]theme.Theme),
+		Features:   make(map[string]FeatureConfig),
+		TrackUsage: true,
+	}
+}
+
+// DefaultPath returns the configuration file location in the config directory
+func DefaultPath() (string, error) {
+	dir, err := store.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, File), nil
+}
+
+// Load reads the configuration at path on top of the defaults. When
+// mustExist is false a missing file yields the defaults. The result is not
+// validated so callers can report every problem at once.
+func Load(path string, mustExist bool) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !mustExist {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := Decode(bytes.NewReader(data), cfg); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Decode reads YAML configuration from r into cfg, rejecting unknown fields
+func Decode(r io.Reader, cfg *Config) error {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.UI.UndoSize <= 0 {
+		errs = append(errs, fmt.Errorf("ui.undo_size must be positive, got %d", c.UI.UndoSize))
+	}
+	if c.UI.HistorySize <= 0 {
+		errs = append(errs, fmt.Errorf("ui.history_size must be positive, got %d", c.UI.HistorySize))
+	}
+	if c.UI.InputWidth != 0 && c.UI.InputWidth < 10 {
+		errs = append(errs, fmt.Errorf("ui.input_width must be 0 (fit the window) or at least 10, got %d", c.UI.InputWidth))
+	}
+	if c.UI.LiveDebounce < 0 {
+		errs = append(errs, fmt.Errorf("ui.live_debounce must not be negative"))
+	}
+	if !theme.Exists(c.UI.Theme, c.Themes) {
+		errs = append(errs, fmt.Errorf("ui.theme: unknown theme %q", c.UI.Theme))
+	}
+	if _, err := theme.Resolve(c.Themes); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := feature.ParseSortOrder(c.UI.FeatureOrder); err != nil {
+		errs = append(errs, fmt.Errorf("ui.feature_order: %w", err))
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout must not be negative"))
+	}
+
+	// Map order varies, sorting keeps the reported errors stable
+	actions := make([]string, 0, len(c.Keys))
+	for action := range c.Keys {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		if len(c.Keys[action]) == 0 {
+			errs = append(errs, fmt.Errorf("keys.%s must list at least one key", action))
+		}
+	}
+
+	ids := make([]string, 0, len(c.Features))
+	for id := range c.Features {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if c.Features[id].Timeout < 0 {
+			errs = append(errs, fmt.Errorf("features.%s.timeout must not be negative", id))
+		}
+	}
+
+	return errors.Join(errs...)
+}
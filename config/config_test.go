@@ -0,0 +1,163 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), File)
+
+	cfg, err := Load(path, false)
+	if err != nil {
+		t.Fatalf("Expected defaults for missing file, got %v", err)
+	}
+	if cfg.UI.UndoSize != 50 || cfg.UI.InputWidth != 0 || !cfg.UI.RestoreTabs {
+		t.Errorf("Expected default UI settings, got %+v", cfg.UI)
+	}
+	if !cfg.TrackUsage {
+		t.Error("Expected usage tracking by default")
+	}
+
+	if _, err := Load(path, true); err == nil {
+		t.Error("Expected error when an explicit config file is missing")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), File)
+	data := `
+ui:
+  undo_size: 100
+  input_width: 50
+  theme: mine
+  feature_order: most-used
+  restore_tabs: false
+timeout: 45s
+keys:
+  execute: [enter, ctrl+j]
+themes:
+  mine:
+    extends: light
+    title: "#005f87"
+features:
+  collision:
+    timeout: 2m
+    options:
+      iterations: 5000
+  timezone:
+    disabled: true
+plugins:
+  dir: ~/bhelper-plugins
+track_usage: false
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path, true)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.UI.UndoSize != 100 || cfg.UI.InputWidth != 50 {
+		t.Errorf("Expected UI overrides, got %+v", cfg.UI)
+	}
+	if cfg.UI.RestoreTabs {
+		t.Error("Expected tab restoring to be disabled")
+	}
+	if cfg.UI.HistorySize != 500 {
+		t.Errorf("Expected default history size to be kept, got %d", cfg.UI.HistorySize)
+	}
+	if cfg.UI.Theme != "mine" || cfg.Themes["mine"].Title != "#005f87" {
+		t.Errorf("Expected custom theme, got %q %+v", cfg.UI.Theme, cfg.Themes)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+	if time.Duration(cfg.Timeout) != 45*time.Second {
+		t.Errorf("Expected 45s timeout, got %v", time.Duration(cfg.Timeout))
+	}
+	if got := cfg.Keys["execute"]; len(got) != 2 {
+		t.Errorf("Expected 2 execute keys, got %v", got)
+	}
+
+	collision := cfg.Features["collision"]
+	if time.Duration(collision.Timeout) != 2*time.Minute {
+		t.Errorf("Expected 2m collision timeout, got %v", time.Duration(collision.Timeout))
+	}
+	if collision.Options["iterations"] != 5000 {
+		t.Errorf("Expected iterations option, got %v", collision.Options["iterations"])
+	}
+	if !cfg.Features["timezone"].Disabled || cfg.UI.FeatureOrder != "most-used" {
+		t.Errorf("Expected disabled timezone and most-used order, got %+v %q", cfg.Features["timezone"], cfg.UI.FeatureOrder)
+	}
+	if cfg.TrackUsage {
+		t.Error("Expected usage tracking to be disabled")
+	}
+	if cfg.Plugins.Dir != "~/bhelper-plugins" || cfg.Plugins.Disabled {
+		t.Errorf("Expected plugins directory, got %+v", cfg.Plugins)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"unknown field", "ui:\n  colour: red\n", "colour"},
+		{"invalid duration", "timeout: soon\n", "invalid duration"},
+		{"invalid ui value", "ui:\n  undo_size: 0\n  input_width: 5\n", "ui.undo_size"},
+		{"empty key list", "keys:\n  execute: []\n", "keys.execute"},
+		{"unknown theme", "ui:\n  theme: solarized\n", "ui.theme"},
+		{"unknown feature order", "ui:\n  feature_order: random\n", "ui.feature_order"},
+		{"invalid theme color", "themes:\n  mine:\n    title: blue\n", "themes.mine"},
+		{"unknown theme color", "themes:\n  mine:\n    titel: \"12\"\n", "titel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			err := Decode(strings.NewReader(tt.data), cfg)
+			if err == nil {
+				err = cfg.Validate()
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	cfg := Default()
+	cfg.UI.UndoSize = 0
+	cfg.UI.InputWidth = 5
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	if !strings.Contains(err.Error(), "undo_size") || !strings.Contains(err.Error(), "input_width") {
+		t.Errorf("Expected both errors to be reported, got %v", err)
+	}
+}
+
+func TestValidateErrorOrder(t *testing.T) {
+	cfg := Default()
+	cfg.Keys = map[string][]string{"undo": {}, "back": {}, "quit": {}, "execute": {}}
+
+	want := "keys.back must list at least one key\n" +
+		"keys.execute must list at least one key\n" +
+		"keys.quit must list at least one key\n" +
+		"keys.undo must list at least one key"
+	for range 10 {
+		if err := cfg.Validate(); err == nil || err.Error() != want {
+			t.Fatalf("Expected errors in key order, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bhelper/feature"
+	"bhelper/recording"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// liveTickMsg fires once the debounce delay after an edit has passed
+type liveTickMsg struct {
+	seq int
+}
+
+// resultMsg carries the outcome of a background feature execution
+type resultMsg struct {
+	runID int
+	// featureID is the feature that ran, which may no longer be selected
+	featureID string
+	input     string
+	result    *feature.Result
+	err       error
+}
+
+// progressMsg reports the progress of a background feature execution
+type progressMsg struct {
+	runID    int
+	fraction float64
+	progress <-chan float64
+}
+
+// timeoutPolicy resolves the execution timeout of each feature. An override
+// applies to every feature, otherwise per-feature values win over the
+// global default, which wins over the timeout the feature declares itself.
+type timeoutPolicy struct {
+	override   time.Duration
+	global     time.Duration
+	perFeature map[string]time.Duration
+}
+
+// For returns the timeout to apply to a feature
+func (p timeoutPolicy) For(f feature.Feature) time.Duration {
+	if p.override > 0 {
+		return p.override
+	}
+	if d, ok := p.perFeature[f.ID()]; ok {
+		return d
+	}
+	if p.global > 0 {
+		return p.global
+	}
+	return feature.TimeoutOf(f)
+}
+
+// parse merges a timeout specification into the policy, either a single
+// duration ("30s") overriding every feature or a list of per-feature
+// durations ("collision=2m,time=1s")
+func (p *timeoutPolicy) parse(spec string) error {
+	if p.perFeature == nil {
+		p.perFeature = make(map[string]time.Duration)
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil
+	}
+
+	if !strings.Contains(spec, "=") {
+		d, err := parsePositiveDuration(spec)
+		if err != nil {
+			return err
+		}
+		p.override = d
+		return nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		id, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || id == "" {
+			return fmt.Errorf("invalid timeout %q: expected feature=duration", part)
+		}
+		d, err := parsePositiveDuration(value)
+		if err != nil {
+			return err
+		}
+		p.perFeature[id] = d
+	}
+	return nil
+}
+
+func parsePositiveDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %v", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be positive", s)
+	}
+	return d, nil
+}
+
+// errTimedOut is returned by runFeature when the timeout expires
+var errTimedOut = errors.New("timed out")
+
+// runFeature executes a feature bounded by timeout, translating a deadline
+// into a readable error
+func runFeature(ctx context.Context, f feature.Feature, input string, timeout time.Duration, progress feature.ProgressFunc) (*feature.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := feature.Run(ctx, f, input, progress)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w after %s", errTimedOut, timeout)
+	}
+	return result, err
+}
+
+// executeCmd runs a feature in the background, publishing progress on the
+// given channel and closing it once the run completes
+func executeCmd(ctx context.Context, f feature.Feature, input string, runID int, timeout time.Duration, progress chan<- float64) tea.Cmd {
+	return func() tea.Msg {
+		defer close(progress)
+
+		result, err := runFeature(ctx, f, input, timeout, func(fraction float64) {
+			// Drop updates the UI has not caught up with rather than block the feature
+			select {
+			case progress <- fraction:
+			default:
+			}
+		})
+		return resultMsg{runID: runID, featureID: f.ID(), input: input, result: result, err: err}
+	}
+}
+
+// listenProgress waits for the next progress update of a run
+func listenProgress(runID int, progress <-chan float64) tea.Cmd {
+	return func() tea.Msg {
+		fraction, ok := <-progress
+		if !ok {
+			return nil
+		}
+		return progressMsg{runID: runID, fraction: fraction, progress: progress}
+	}
+}
+
+// startExecution runs the current input in the background and shows the
+// spinner until the result arrives
+func (c CLI) startExecution() (CLI, tea.Cmd) {
+	input := c.inputValue()
+	if input == "" {
+		return c, nil
+	}
+
+	c.cancelExecution()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := make(chan float64, 1)
+
+	c.runID = c.nextRunID()
+	c.running = true
+	c.cancelRun = cancel
+	c.progress = 0
+
+	timeout := c.timeouts.For(c.selectedFeature)
+	return c, tea.Batch(
+		executeCmd(ctx, c.selectedFeature, input, c.runID, timeout, progress),
+		listenProgress(c.runID, progress),
+		c.spinner.Tick,
+	)
+}
+
+// startRecordedExecution runs the current input like startExecution,
+// counts it in the usage statistics and adds the run to the session log
+// given with --record once it completes. An empty input starts no run and
+// is not counted.
+func (c CLI) startRecordedExecution() (CLI, tea.Cmd) {
+	last := c.runID
+	c, cmd := c.startExecution()
+	if c.runID == last {
+		return c, cmd
+	}
+
+	c.recordUsage()
+	if c.recorder != nil {
+		c.recordRun = c.runID
+	}
+	return c, cmd
+}
+
+// cancelExecution stops any in-flight execution and drops its result
+func (c *CLI) cancelExecution() {
+	if c.cancelRun != nil {
+		c.cancelRun()
+		c.cancelRun = nil
+	}
+	c.runID = c.nextRunID()
+	c.running = false
+}
+
+// nextRunID hands out a run ID that no tab has used before
+func (c *CLI) nextRunID() int {
+	c.runSeq++
+	return c.runSeq
+}
+
+// inputChanged reacts to an edit of the input: live features are re-run
+// after a debounce delay unless the input is invalid, others have their
+// stale output cleared
+func (c CLI) inputChanged() (CLI, tea.Cmd) {
+	c.cancelExecution()
+
+	// Suggestions follow typing, the caller shows them again for keystrokes
+	c.completionIndex = 0
+	c.hideCompletion = true
+
+	// Invalid input is already explained below the input
+	if !feature.IsLive(c.selectedFeature) || c.inputValue() == "" || c.validateInput() != nil {
+		c.clearOutput()
+		return c, nil
+	}
+
+	c.editSeq++
+	return c, liveTick(c.liveDebounce, c.editSeq)
+}
+
+// liveTick waits for the debounce delay after the edit numbered seq
+func liveTick(delay time.Duration, seq int) tea.Cmd {
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return liveTickMsg{seq: seq}
+	})
+}
+
+// handleResult stores the result of a background execution unless a newer
+// one has been started since
+func (c CLI) handleResult(msg resultMsg) (CLI, tea.Cmd) {
+	if msg.runID != c.runID {
+		return c, nil
+	}
+
+	c.running = false
+	c.cancelRun = nil
+	if msg.runID == c.recordRun {
+		c.logRun(msg)
+	}
+	if msg.err != nil {
+		c.result = nil
+		c.output = fmt.Sprintf("Error: %v", msg.err)
+		return c, nil
+	}
+
+	c.result = msg.result
+	c.resultInput = msg.input
+	c.output = c.renderOutput()
+	return c, nil
+}
+
+// logRun adds a completed run to the session log
+func (c *CLI) logRun(msg resultMsg) {
+	entry := recording.NewEntry(time.Now(), msg.featureID, msg.input, msg.result, msg.err)
+	if err := c.recorder.Record(entry); err != nil {
+		c.status = fmt.Sprintf("Run not recorded: %v", err)
+	}
+}
+
+// handleProgress records the progress of the current run and waits for more
+func (c CLI) handleProgress(msg progressMsg) (CLI, tea.Cmd) {
+	if msg.runID == c.runID {
+		c.progress = msg.fraction
+	}
+	return c, listenProgress(msg.runID, msg.progress)
+}
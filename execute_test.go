@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bhelper/feature"
+	"bhelper/recording"
+	"bhelper/store"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stub is a feature returning its input after delay, declaring a timeout
+// when set
+type stub struct {
+	id      string
+	timeout time.Duration
+	delay   time.Duration
+}
+
+func (s stub) ID() string                  { return s.id }
+func (s stub) Name() string                { return strings.ToUpper(s.id) }
+func (s stub) Description() string         { return "Repeats the input" }
+func (s stub) Help() string                { return "Type anything" }
+func (s stub) Examples() []feature.Example { return []feature.Example{{Input: "hi"}} }
+func (s stub) Timeout() time.Duration      { return s.timeout }
+
+func (s stub) Execute(input string) (string, error) {
+	time.Sleep(s.delay)
+	return "echo: " + input, nil
+}
+
+func TestTimeoutPolicyFor(t *testing.T) {
+	declared := stub{id: "slow", timeout: 5 * time.Minute}
+	plain := stub{id: "plain"}
+
+	tests := []struct {
+		name   string
+		policy timeoutPolicy
+		f      feature.Feature
+		want   time.Duration
+	}{
+		{"declared", timeoutPolicy{}, declared, 5 * time.Minute},
+		{"default", timeoutPolicy{}, plain, feature.DefaultTimeout},
+		{"global over declared", timeoutPolicy{global: time.Second}, declared, time.Second},
+		{"per feature over global", timeoutPolicy{global: time.Second, perFeature: map[string]time.Duration{"slow": time.Minute}}, declared, time.Minute},
+		{"per feature of another", timeoutPolicy{perFeature: map[string]time.Duration{"slow": time.Minute}}, plain, feature.DefaultTimeout},
+		{"override over all", timeoutPolicy{override: 3 * time.Second, global: time.Second, perFeature: map[string]time.Duration{"slow": time.Minute}}, declared, 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.For(tt.f); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTimeoutPolicyParse(t *testing.T) {
+	tests := []struct {
+		spec       string
+		override   time.Duration
+		perFeature map[string]time.Duration
+		err        string
+	}{
+		{spec: ""},
+		{spec: "30s", override: 30 * time.Second},
+		{spec: " collision=2m, time=1s ", perFeature: map[string]time.Duration{"collision": 2 * time.Minute, "time": time.Second}},
+		{spec: "soon", err: "invalid timeout"},
+		{spec: "-5s", err: "must be positive"},
+		{spec: "collision=2m,=1s", err: "expected feature=duration"},
+		{spec: "collision=2m,time", err: "expected feature=duration"},
+		{spec: "collision=0s", err: "must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			var p timeoutPolicy
+			err := p.parse(tt.spec)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if p.override != tt.override {
+				t.Errorf("Expected override %s, got %s", tt.override, p.override)
+			}
+			if len(p.perFeature) != len(tt.perFeature) {
+				t.Errorf("Expected %v, got %v", tt.perFeature, p.perFeature)
+			}
+			for id, d := range tt.perFeature {
+				if p.perFeature[id] != d {
+					t.Errorf("Expected %s for %s, got %s", d, id, p.perFeature[id])
+				}
+			}
+		})
+	}
+}
+
+func TestTimeoutPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := `timeout: 10s
+plugins:
+  disabled: true
+features:
+  time:
+    timeout: 2m
+`
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  string
+		want map[string]time.Duration
+	}{
+		{"config only", "", map[string]time.Duration{"time": 2 * time.Minute, "echo": 10 * time.Second}},
+		{"env duration overrides all", "30s", map[string]time.Duration{"time": 30 * time.Second, "echo": 30 * time.Second}},
+		{"env per feature over config", "time=5s", map[string]time.Duration{"time": 5 * time.Second, "echo": 10 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BHELPER_TIMEOUT", tt.env)
+
+			registry := feature.NewFeatureRegistry()
+			registry.Register(stub{id: "time"})
+			registry.Register(stub{id: "echo", timeout: time.Hour})
+
+			s, err := loadSettings(registry, path, true)
+			if err != nil {
+				t.Fatalf("loadSettings failed: %v", err)
+			}
+			for id, want := range tt.want {
+				f, _ := registry.Get(id)
+				if got := s.timeouts.For(f); got != want {
+					t.Errorf("Expected %s for %s, got %s", want, id, got)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteCountsUsage(t *testing.T) {
+	registry := feature.NewFeatureRegistry()
+	registry.Register(stub{id: "echo"})
+	usage := store.NewUsage("")
+
+	c := NewCLI(registry, cliOptions{keys: defaultKeyMap(), usage: usage})
+	m, _ := c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	c = m.(CLI)
+	if c.mode != ModeFeatureExecute {
+		t.Fatalf("Expected Enter to open the feature, got mode %v", c.mode)
+	}
+
+	// Enter on an empty input runs nothing
+	m, _ = c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	c = m.(CLI)
+	if c.running || usage.Count("echo") != 0 {
+		t.Fatalf("Expected no run to be counted, got %d", usage.Count("echo"))
+	}
+
+	c.setInput("hi")
+	m, _ = c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	c = m.(CLI)
+	c.cancelExecution()
+	if usage.Count("echo") != 1 {
+		t.Errorf("Expected one counted run, got %d", usage.Count("echo"))
+	}
+}
+
+// resultOf runs the commands of a batch until one yields the result of a run
+func resultOf(t *testing.T, cmd tea.Cmd) resultMsg {
+	t.Helper()
+
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c == nil {
+				continue
+			}
+			if msg, ok := c().(resultMsg); ok {
+				return msg
+			}
+		}
+	}
+	t.Fatal("Expected the command to run a feature")
+	return resultMsg{}
+}
+
+func TestRecordedRunKeepsFeature(t *testing.T) {
+	registry := feature.NewFeatureRegistry()
+	registry.Register(stub{id: "echo"})
+	registry.Register(stub{id: "other"})
+
+	var log bytes.Buffer
+	c := NewCLI(registry, cliOptions{keys: defaultKeyMap(), recorder: recording.NewRecorder(&log)})
+	m, _ := c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	c = m.(CLI)
+	c.setInput("hi")
+	m, cmd := c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	c = m.(CLI)
+
+	// The run completes after another feature was selected
+	msg := resultOf(t, cmd)
+	c.selectedFeature, _ = registry.Get("other")
+	c.Update(msg)
+
+	entries, err := recording.Read(&log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Feature != "echo" {
+		t.Errorf("Expected the run logged for echo, got %+v", entries)
+	}
+}
+
+func TestLeavingFeatureCancelsRun(t *testing.T) {
+	registry := feature.NewFeatureRegistry()
+	registry.Register(stub{id: "echo"})
+	registry.Register(stub{id: "other"})
+	keys := defaultKeyMap()
+
+	c := NewCLI(registry, cliOptions{keys: keys})
+	m, _ := c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	c = m.(CLI)
+	c.setInput("hi")
+	m, cmd := c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	c = m.(CLI)
+	msg := resultOf(t, cmd)
+
+	// Through the help screen back to the list and into another feature
+	for _, k := range []tea.KeyMsg{
+		{Type: tea.KeyCtrlH},
+		{Type: tea.KeyEsc},
+		{Type: tea.KeyDown},
+		{Type: tea.KeyEnter},
+	} {
+		m, _ = c.Update(k)
+		c = m.(CLI)
+	}
+	if c.mode != ModeFeatureExecute || c.selectedFeature.ID() != "other" {
+		t.Fatalf("Expected other to be open, got %s in mode %v", c.selectedFeature.ID(), c.mode)
+	}
+	if c.running {
+		t.Error("Expected the run to be cancelled")
+	}
+
+	m, _ = c.Update(msg)
+	c = m.(CLI)
+	if c.result != nil || c.output != "" {
+		t.Errorf("Expected the late result to be dropped, got %q", c.output)
+	}
+}
+
+func TestPresetKeysFollowConfig(t *testing.T) {
+	registry := feature.NewFeatureRegistry()
+	registry.Register(stub{id: "echo"})
+	presets := store.NewPresets("")
+	if err := presets.Add("echo", "short", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	keys := defaultKeyMap()
+	if err := keys.apply(map[string][]string{"preset_delete": {"x"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCLI(registry, cliOptions{keys: keys, presets: presets})
+	m, _ := c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	c = m.(CLI)
+	m, _ = c.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	c = m.(CLI)
+
+	m, _ = c.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	c = m.(CLI)
+	if len(presets.List("echo")) != 1 {
+		t.Fatal("Expected the default delete key to be replaced")
+	}
+	c.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if len(presets.List("echo")) != 0 {
+		t.Errorf("Expected the configured key to delete the preset, got %+v", presets.List("echo"))
+	}
+}
+
+func TestClosingPresetsFocusesInput(t *testing.T) {
+	registry := feature.NewFeatureRegistry()
+	registry.Register(stub{id: "echo"})
+
+	c := NewCLI(registry, cliOptions{keys: defaultKeyMap(), presets: store.NewPresets("")})
+	m, _ := c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	c = m.(CLI)
+	m, _ = c.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	c = m.(CLI)
+
+	m, cmd := c.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	c = m.(CLI)
+	if c.presetMode != presetsClosed || !c.textInput.Focused() {
+		t.Fatal("Expected Esc to close the presets and focus the input")
+	}
+	if cmd == nil {
+		t.Error("Expected a command to restart the cursor blink")
+	}
+}
+
+func TestSaveResultKeepsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "echo.txt")
+	if err := os.WriteFile(path, []byte("keep\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCLI(feature.NewFeatureRegistry(), cliOptions{keys: defaultKeyMap()})
+	c.selectedFeature = stub{id: "echo"}
+	c.result = feature.TextResult("new")
+
+	if err := c.saveResult(path, feature.FormatText); err == nil || !strings.Contains(err.Error(), "file exists") {
+		t.Errorf("Expected a file exists error, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep\n" {
+		t.Errorf("Expected the file to be kept, got %q", data)
+	}
+
+	other := filepath.Join(filepath.Dir(path), "other.txt")
+	if err := c.saveResult(other, feature.FormatText); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(other); string(data) != "new\n" {
+		t.Errorf("Expected the result to be saved, got %q", data)
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bhelper/feature"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// exportMode is the state of the copy picker and save prompt in the
+// execute view
+type exportMode int
+
+const (
+	exportClosed exportMode = iota
+	exportCopying
+	exportSaving
+)
+
+// formatExtensions maps output formats to file extensions
+var formatExtensions = map[feature.Format]string{
+	feature.FormatText: ".txt",
+	feature.FormatJSON: ".json",
+	feature.FormatYAML: ".yaml",
+	feature.FormatCSV:  ".csv",
+}
+
+// clipboardMsg reports the outcome of a clipboard write and the clipboard
+// that was written
+type clipboardMsg struct {
+	what   string
+	method string
+	err    error
+}
+
+// terminalOutput is the output the interactive interface is drawn to.
+// Writes are serialized, so escape sequences written by commands never
+// interleave with a frame being drawn.
+type terminalOutput struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func newTerminalOutput(f *os.File) *terminalOutput {
+	return &terminalOutput{File: f}
+}
+
+func (t *terminalOutput) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(p)
+}
+
+// isTerminal reports whether the output is a terminal rather than a file
+// or pipe
+func (t *terminalOutput) isTerminal() bool {
+	if t == nil {
+		return false
+	}
+	info, err := t.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// copyEntry is a choice in the copy picker
+type copyEntry struct {
+	label string
+	text  string
+}
+
+// encodeResult returns the last result without styling in the given format
+func (c CLI) encodeResult(format feature.Format) (string, error) {
+	var b strings.Builder
+	doc := feature.NewDocument(c.selectedFeature.ID(), c.resultInput, c.result)
+	if err := feature.Encode(&b, doc, format); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// copyEntries lists the whole result followed by each of its fields
+func (c CLI) copyEntries() []copyEntry {
+	if c.result == nil {
+		return nil
+	}
+
+	whole, err := c.encodeResult(c.format)
+	if err != nil {
+		whole = c.result.String()
+	}
+
+	entries := []copyEntry{{label: fmt.Sprintf("Whole result (%s)", c.format), text: whole}}
+	for _, f := range c.result.Fields() {
+		entries = append(entries, copyEntry{
+			label: fmt.Sprintf("%s: %s", f.Label, f.Display),
+			text:  feature.FormatValue(f.Value),
+		})
+	}
+	return entries
+}
+
+// openCopy shows the copy picker for the last result
+func (c CLI) openCopy() (CLI, tea.Cmd) {
+	if c.result == nil {
+		c.status = "Nothing to copy yet"
+		return c, nil
+	}
+
+	c.exportMode = exportCopying
+	c.copyIndex = 0
+	c.blurInput()
+	return c, nil
+}
+
+// openSave shows the prompt for the file to save the last result to
+func (c CLI) openSave() (CLI, tea.Cmd) {
+	if c.result == nil {
+		c.status = "Nothing to save yet"
+		return c, nil
+	}
+
+	c.exportMode = exportSaving
+	c.saveFormat = c.format
+	c.savePath.SetValue(c.selectedFeature.ID() + formatExtensions[c.format])
+	c.savePath.CursorEnd()
+	c.savePath.Focus()
+	c.blurInput()
+	return c, nil
+}
+
+// closeExport hides the copy picker or save prompt
+func (c CLI) closeExport() (CLI, tea.Cmd) {
+	c.exportMode = exportClosed
+	c.savePath.Blur()
+	return c, c.focusInput()
+}
+
+// updateExport handles keys while the copy picker or save prompt is open
+func (c CLI) updateExport(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	if c.exportMode == exportSaving {
+		return c.updateSave(msg)
+	}
+
+	entries := c.copyEntries()
+
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Back, c.keys.Copy):
+		return c.closeExport()
+
+	case key.Matches(msg, c.keys.Up):
+		if c.copyIndex > 0 {
+			c.copyIndex--
+		}
+
+	case key.Matches(msg, c.keys.Down):
+		if c.copyIndex < len(entries)-1 {
+			c.copyIndex++
+		}
+
+	case key.Matches(msg, c.keys.Select):
+		if c.copyIndex >= len(entries) {
+			return c.closeExport()
+		}
+		e := entries[c.copyIndex]
+		what := "result"
+		if c.copyIndex > 0 {
+			what = c.result.Fields()[c.copyIndex-1].Label
+		}
+		c, focus := c.closeExport()
+		return c, tea.Batch(focus, copyToClipboard(c.terminal, e.text, what))
+	}
+
+	return c, nil
+}
+
+// updateSave handles typing the path of the file to save to. Tab or the
+// output format key cycles the format; a known extension in the path takes
+// precedence.
+func (c CLI) updateSave(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Back):
+		return c.closeExport()
+
+	case msg.Type == tea.KeyTab, key.Matches(msg, c.keys.OutputFormat):
+		path := c.savePath.Value()
+		if _, ok := formatFromPath(path); ok {
+			path = strings.TrimSuffix(path, filepath.Ext(path))
+		}
+		c.saveFormat = c.saveFormat.Next()
+		c.savePath.SetValue(path + formatExtensions[c.saveFormat])
+		c.savePath.CursorEnd()
+		return c, nil
+
+	case key.Matches(msg, c.keys.Execute):
+		path := strings.TrimSpace(c.savePath.Value())
+		if path == "" {
+			c.status = "Enter a file name"
+			return c, nil
+		}
+
+		format := c.saveFormat
+		if f, ok := formatFromPath(path); ok {
+			format = f
+		}
+		if err := c.saveResult(path, format); err != nil {
+			c.status = err.Error()
+			return c, nil
+		}
+		c.status = fmt.Sprintf("Saved %s to %s", format, path)
+		return c.closeExport()
+	}
+
+	var cmd tea.Cmd
+	c.savePath, cmd = c.savePath.Update(msg)
+	return c, cmd
+}
+
+// saveResult writes the last result to path in the given format
+func (c CLI) saveResult(path string, format feature.Format) error {
+	out, err := c.encodeResult(format)
+	if err != nil {
+		return err
+	}
+
+	path, err = expandHome(path)
+	if err != nil {
+		return err
+	}
+
+	// Never replace an existing file, the user picks another name instead
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s: file exists", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save result: %w", err)
+	}
+	if _, err := f.WriteString(out + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save result: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to save result: %w", err)
+	}
+	return nil
+}
+
+// expandHome replaces a leading "~/" with the home directory
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// formatFromPath infers the output format from a file extension
+func formatFromPath(path string) (feature.Format, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yml" {
+		return feature.FormatYAML, true
+	}
+	for f, e := range formatExtensions {
+		if e == ext {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// copyToClipboard writes text to the terminal clipboard with OSC52, which
+// also works over SSH. The local system clipboard is only used when the
+// interface is not drawn to a terminal.
+func copyToClipboard(out *terminalOutput, text, what string) tea.Cmd {
+	return func() tea.Msg {
+		if out.isTerminal() {
+			seq := osc52.New(text)
+			switch {
+			case os.Getenv("TMUX") != "":
+				seq = seq.Tmux()
+			case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+				seq = seq.Screen()
+			}
+			if _, err := seq.WriteTo(out); err == nil {
+				return clipboardMsg{what: what, method: "OSC52"}
+			}
+		}
+
+		if clipboard.Unsupported {
+			return clipboardMsg{what: what, err: errors.New("no terminal or system clipboard available")}
+		}
+		if err := clipboard.WriteAll(text); err != nil {
+			return clipboardMsg{what: what, err: err}
+		}
+		return clipboardMsg{what: what, method: "system clipboard"}
+	}
+}
+
+// handleClipboard reports the outcome of a copy in the status line
+func (c CLI) handleClipboard(msg clipboardMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		c.status = fmt.Sprintf("Copy failed: %v", msg.err)
+	} else {
+		c.status = fmt.Sprintf("Copied %s to clipboard (%s)", msg.what, msg.method)
+	}
+	return c, nil
+}
+
+// renderExport shows the copy picker or save prompt
+func (c CLI) renderExport() string {
+	var s strings.Builder
+
+	if c.exportMode == exportSaving {
+		label := fmt.Sprintf("Save %s to: ", c.saveFormat)
+		s.WriteString(c.styles.label.Render(label) + c.savePath.View() + "\n")
+		return s.String()
+	}
+
+	s.WriteString(c.styles.section.Render("Copy:") + "\n")
+	for i, e := range c.copyEntries() {
+		cursor := "  "
+		style := c.styles.feature
+		if i == c.copyIndex {
+			cursor = "→ "
+			style = c.styles.selectedFeature
+		}
+		s.WriteString(style.Render(cursor+e.label) + "\n")
+	}
+	return s.String()
+}
@@ -25,6 +25,34 @@ func (ca *CharacterAnalyzer) Description() string {
 	return "Analyze text encoding (UTF-8, UTF-16, ASCII, hex, binary)"
 }
 
+func (ca *CharacterAnalyzer) Category() string {
+	return "Text"
+}
+
+func (ca *CharacterAnalyzer) Tags() []string {
+	return []string{"encoding", "unicode", "utf8", "hex", "binary"}
+}
+
+func (ca *CharacterAnalyzer) Outputs() []Output {
+	return []Output{
+		{Key: "runes", Description: "Number of characters"},
+		{Key: "utf8_bytes", Description: "Size in UTF-8"},
+		{Key: "utf16_bytes", Description: "Size in UTF-16"},
+		{Key: "utf32_bytes", Description: "Size in UTF-32"},
+		{Key: "decimal", Description: "Code points in decimal"},
+		{Key: "hexadecimal", Description: "Code points in hexadecimal"},
+		{Key: "binary", Description: "Code points in binary"},
+	}
+}
+
+func (ca *CharacterAnalyzer) Live() bool {
+	return true
+}
+
+func (ca *CharacterAnalyzer) Multiline() bool {
+	return true
+}
+
 func (ca *CharacterAnalyzer) Help() string {
 	return `Character Analyzer examines text and provides detailed encoding information:
 
@@ -52,23 +80,38 @@ func (ca *CharacterAnalyzer) Execute(input string) (string, error) {
 		return "Please provide some text to analyze", nil
 	}
 
-	var result strings.Builder
+	result, err := ca.ExecuteResult(input)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+func (ca *CharacterAnalyzer) ExecuteResult(input string) (*Result, error) {
+	if input == "" {
+		return nil, fmt.Errorf("please provide some text to analyze")
+	}
 
 	runeCount := utf8.RuneCountInString(input)
 	utf8Bytes := len(input)
 	utf16Bytes := calculateUTF16Bytes(input)
 	utf32Bytes := runeCount * 4
 
-	result.WriteString(fmt.Sprintf("Total Runes: %d characters\n", runeCount))
-	result.WriteString(fmt.Sprintf("UTF-8:  %d bytes\n", utf8Bytes))
-	result.WriteString(fmt.Sprintf("UTF-16: %d bytes\n", utf16Bytes))
-	result.WriteString(fmt.Sprintf("UTF-32: %d bytes\n\n", utf32Bytes))
+	result := NewResult("")
 
-	result.WriteString("Decimal:     " + generateDecimal(input) + "\n")
-	result.WriteString("Hexadecimal: " + generateHex(input) + "\n")
-	result.WriteString("Binary:      " + truncate(generateBinary(input), 60) + "\n")
+	result.AddSection("Counts").
+		AddFormatted("runes", "Total Runes", runeCount, fmt.Sprintf("%d characters", runeCount)).
+		AddFormatted("utf8_bytes", "UTF-8", utf8Bytes, fmt.Sprintf("%d bytes", utf8Bytes)).
+		AddFormatted("utf16_bytes", "UTF-16", utf16Bytes, fmt.Sprintf("%d bytes", utf16Bytes)).
+		AddFormatted("utf32_bytes", "UTF-32", utf32Bytes, fmt.Sprintf("%d bytes", utf32Bytes))
 
-	return result.String(), nil
+	binary := generateBinary(input)
+	result.AddSection("Encodings").
+		Add("decimal", "Decimal", generateDecimal(input)).
+		Add("hexadecimal", "Hexadecimal", generateHex(input)).
+		AddFormatted("binary", "Binary", binary, truncate(binary, 60))
+
+	return result, nil
 }
 
 func calculateUTF16Bytes(text string) int {
@@ -2,9 +2,11 @@ package collision
 
 import (
 	"bhelper/feature"
+	"context"
 	"fmt"
 	"math"
 	"math/big"
+	"strings"
 	"time"
 )
 
@@ -28,8 +30,12 @@ type SimResult struct {
 	Probability float64
 }
 
+// DefaultSimulationIterations is the number of IDs generated by the simulation
+const DefaultSimulationIterations = 1000000
+
 type CollisionAnalyzer struct {
-	registry *GeneratorRegistry
+	registry   *GeneratorRegistry
+	iterations int
 }
 
 func NewCollisionAnalyzer() *CollisionAnalyzer {
@@ -38,12 +44,14 @@ func NewCollisionAnalyzer() *CollisionAnalyzer {
 	gen62, _ := NewBase62Generator(10)
 	genSnow, _ := NewSnowflakeGenerator()
 
+	// The built-in generators have distinct names, registering cannot fail
 	reg.Register(gen64)
 	reg.Register(gen62)
 	reg.Register(genSnow)
 
 	return &CollisionAnalyzer{
-		registry: reg,
+		registry:   reg,
+		iterations: DefaultSimulationIterations,
 	}
 }
 
@@ -59,6 +67,49 @@ func (c *CollisionAnalyzer) Description() string {
 	return "Analyze collision probability for ID generation systems"
 }
 
+func (c *CollisionAnalyzer) Category() string {
+	return "Identifiers"
+}
+
+func (c *CollisionAnalyzer) Tags() []string {
+	return []string{"uuid", "snowflake", "base62", "base64", "probability"}
+}
+
+func (c *CollisionAnalyzer) Outputs() []feature.Output {
+	return []feature.Output{
+		{Key: "probability", Description: "Collision probability within one second"},
+		{Key: "expected_collisions", Description: "Expected collisions within one second"},
+		{Key: "p50_seconds", Description: "Seconds until a 50% collision probability"},
+		{Key: "total_space", Description: "Number of possible IDs"},
+	}
+}
+
+// VolatileKeys returns the figures measured by the simulation, which is
+// random
+func (c *CollisionAnalyzer) VolatileKeys(input string) []string {
+	return []string{"sim_collisions", "sim_probability", "probability_difference"}
+}
+
+func (c *CollisionAnalyzer) OptionSpecs() []feature.OptionSpec {
+	return []feature.OptionSpec{
+		{
+			Key:         "iterations",
+			Type:        feature.OptionInt,
+			Default:     DefaultSimulationIterations,
+			Description: "Number of IDs generated by the collision simulation",
+		},
+	}
+}
+
+func (c *CollisionAnalyzer) Configure(opts feature.Options) error {
+	iterations := opts.Int("iterations")
+	if iterations <= 0 {
+		return fmt.Errorf("iterations must be positive, got %d", iterations)
+	}
+	c.iterations = iterations
+	return nil
+}
+
 func (c *CollisionAnalyzer) Help() string {
 	return `Analyzes the probability of ID collisions for various generation schemes.
 
@@ -92,10 +143,80 @@ func (c *CollisionAnalyzer) Examples() []feature.Example {
 }
 
 func (c *CollisionAnalyzer) Execute(input string) (string, error) {
-	config, err := ParseInput(input)
+	result, err := c.ExecuteResult(input)
 	if err != nil {
 		return "", err
 	}
+	return result.String(), nil
+}
+
+func (c *CollisionAnalyzer) ExecuteResult(input string) (*feature.Result, error) {
+	return c.ExecuteContext(context.Background(), input, nil)
+}
+
+func (c *CollisionAnalyzer) Timeout() time.Duration {
+	return 2 * time.Minute
+}
+
+// Validate checks the input format, generator, length and rate unit
+// without running the simulation
+func (c *CollisionAnalyzer) Validate(input string) error {
+	_, _, _, err := c.parse(input)
+	return err
+}
+
+// rateUnits lists the accepted rate units
+var rateUnits = []feature.Completion{
+	{Value: "sec", Description: "IDs per second"},
+	{Value: "min", Description: "IDs per minute"},
+	{Value: "ms", Description: "IDs per millisecond"},
+	{Value: "ns", Description: "IDs per nanosecond"},
+}
+
+// rateUnitList names the accepted rate units in error hints
+func rateUnitList() string {
+	names := make([]string, len(rateUnits))
+	for i, u := range rateUnits {
+		names[i] = u.Value
+	}
+	return strings.Join(names, ", ")
+}
+
+// Complete suggests generator names in the first part of the input and
+// rate units after the '/'
+func (c *CollisionAnalyzer) Complete(input string, cursor int) feature.Completions {
+	before := input[:cursor]
+	start := strings.LastIndexAny(before, ":/") + 1
+	end := len(input)
+	if i := strings.IndexAny(input[cursor:], ":/"); i >= 0 {
+		end = cursor + i
+	}
+	completions := feature.Completions{Start: start, End: end}
+
+	var candidates []feature.Completion
+	switch {
+	case start == 0:
+		for _, name := range c.registry.Names() {
+			candidates = append(candidates, feature.Completion{Value: name, Description: "ID generator"})
+		}
+	case input[start-1] == '/' && strings.Count(before, ":") == 2:
+		candidates = rateUnits
+	}
+	completions.Candidates = feature.MatchPrefix(input[start:cursor], candidates)
+	return completions
+}
+
+// parse resolves an input into its generator and rate per second. Errors
+// are *feature.InputError values pointing at the offending part.
+func (c *CollisionAnalyzer) parse(input string) (*Config, IDGenerator, int64, error) {
+	config, err := ParseInput(input)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	formatEnd := len(config.Format)
+	lengthEnd := strings.Index(input[formatEnd+1:], ":") + formatEnd + 1
+	unitStart := len(input) - len(config.RateUnit)
 
 	var gen IDGenerator
 	switch config.Format {
@@ -106,11 +227,15 @@ func (c *CollisionAnalyzer) Execute(input string) (string, error) {
 	case "snowflake":
 		gen, err = NewSnowflakeGenerator()
 	default:
-		return "", fmt.Errorf("unknown generator: %s", config.Format)
+		return nil, nil, 0, feature.NewInputError(input, 0, formatEnd,
+			fmt.Sprintf("unknown generator: %s", config.Format),
+			"use one of "+strings.Join(c.registry.Names(), ", "))
 	}
 
 	if err != nil {
-		return "", fmt.Errorf("failed to create generator: %v", err)
+		return nil, nil, 0, feature.NewInputError(input, formatEnd+1, lengthEnd,
+			fmt.Sprintf("failed to create generator: %v", err),
+			"use a length of at least 1")
 	}
 
 	var ratePerSec int64
@@ -124,23 +249,32 @@ func (c *CollisionAnalyzer) Execute(input string) (string, error) {
 	case "ns":
 		ratePerSec = config.Rate * 1000000000
 	default:
-		return "", fmt.Errorf("unsupported rate unit: %s", config.RateUnit)
+		return nil, nil, 0, feature.NewInputError(input, unitStart, len(input),
+			fmt.Sprintf("unsupported rate unit: %s", config.RateUnit),
+			"use one of "+rateUnitList())
+	}
+	return config, gen, ratePerSec, nil
+}
+
+func (c *CollisionAnalyzer) ExecuteContext(ctx context.Context, input string, progress feature.ProgressFunc) (*feature.Result, error) {
+	config, gen, ratePerSec, err := c.parse(input)
+	if err != nil {
+		return nil, err
 	}
 
 	totalIDs := ratePerSec
 
 	mathResult, err := CalculateProbability(totalIDs, gen.TotalSpace())
 	if err != nil {
-		return "", fmt.Errorf("calculation error: %v", err)
+		return nil, fmt.Errorf("calculation error: %v", err)
 	}
 
-	simIterations := 1000000
-	simResult, err := SimulateCollisions(gen, simIterations)
+	simResult, err := SimulateCollisionsContext(ctx, gen, c.iterations, progress)
 	if err != nil {
-		return "", fmt.Errorf("simulation error: %v", err)
+		return nil, fmt.Errorf("simulation error: %w", err)
 	}
 
-	return FormatResult(config.Format, config.Length, ratePerSec, mathResult, simResult), nil
+	return BuildResult(config.Format, config.Length, ratePerSec, mathResult, simResult), nil
 }
 
 func CalculateProbability(n int64, N uint64) (*MathResult, error) {
@@ -191,7 +325,15 @@ func CalculateTimeToCollision(N uint64, rate int64) *TimeResult {
 	}
 }
 
+// progressInterval is how many iterations run between cancellation checks
+// and progress reports
+const progressInterval = 10000
+
 func SimulateCollisions(gen IDGenerator, maxIterations int) (*SimResult, error) {
+	return SimulateCollisionsContext(context.Background(), gen, maxIterations, nil)
+}
+
+func SimulateCollisionsContext(ctx context.Context, gen IDGenerator, maxIterations int, progress feature.ProgressFunc) (*SimResult, error) {
 	if maxIterations <= 0 {
 		return nil, fmt.Errorf("maxIterations must be positive")
 	}
@@ -200,6 +342,15 @@ func SimulateCollisions(gen IDGenerator, maxIterations int) (*SimResult, error)
 	collisions := 0
 
 	for i := 0; i < maxIterations; i++ {
+		if i%progressInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if progress != nil {
+				progress(float64(i) / float64(maxIterations))
+			}
+		}
+
 		id := gen.Generate()
 		if seen[id] {
 			collisions++
@@ -208,6 +359,10 @@ func SimulateCollisions(gen IDGenerator, maxIterations int) (*SimResult, error)
 		}
 	}
 
+	if progress != nil {
+		progress(1)
+	}
+
 	probability := float64(collisions) / float64(maxIterations)
 
 	return &SimResult{
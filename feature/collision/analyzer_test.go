@@ -1,6 +1,12 @@
 package collision
 
-import "testing"
+import (
+	"bhelper/feature"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
 
 func TestNewCollisionAnalyzer(t *testing.T) {
 	analyzer := NewCollisionAnalyzer()
@@ -74,3 +80,113 @@ func TestCollisionAnalyzerNanosecond(t *testing.T) {
 		t.Error("Expected non-empty result")
 	}
 }
+
+func TestSimulateCollisionsContextCancelled(t *testing.T) {
+	gen, _ := NewBase62Generator(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := SimulateCollisionsContext(ctx, gen, 1000000, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSimulateCollisionsContextProgress(t *testing.T) {
+	gen, _ := NewBase62Generator(10)
+
+	var last float64
+	calls := 0
+	_, err := SimulateCollisionsContext(context.Background(), gen, 50000, func(fraction float64) {
+		if fraction < last {
+			t.Errorf("Progress went backwards: %f after %f", fraction, last)
+		}
+		last = fraction
+		calls++
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls == 0 || last != 1 {
+		t.Errorf("Expected progress to reach 1, got %f after %d calls", last, calls)
+	}
+}
+
+func TestCollisionAnalyzerConfigure(t *testing.T) {
+	analyzer := NewCollisionAnalyzer()
+
+	if err := feature.Configure(analyzer, map[string]any{"iterations": 2000}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if analyzer.iterations != 2000 {
+		t.Errorf("Expected 2000 iterations, got %d", analyzer.iterations)
+	}
+
+	if err := feature.Configure(analyzer, map[string]any{"iterations": 0}); err == nil {
+		t.Error("Expected error for non-positive iterations")
+	}
+	if err := feature.Configure(analyzer, map[string]any{"iterations": "many"}); err == nil {
+		t.Error("Expected error for non-integer iterations")
+	}
+	if err := feature.Configure(analyzer, map[string]any{"rounds": 5}); err == nil {
+		t.Error("Expected error for unknown option")
+	}
+}
+
+func TestCollisionAnalyzerComplete(t *testing.T) {
+	analyzer := NewCollisionAnalyzer()
+
+	tests := []struct {
+		name   string
+		input  string
+		cursor int
+		want   []string
+		start  int
+		end    int
+	}{
+		{"generator prefix", "base6", 5, []string{"base64", "base62"}, 0, 5},
+		{"generator before length", "sn:10:5/sec", 2, []string{"snowflake"}, 0, 2},
+		{"length", "base62:1", 8, nil, 7, 8},
+		{"rate", "base62:10:5", 11, nil, 10, 11},
+		{"rate unit", "base62:10:5/m", 13, []string{"min", "ms"}, 12, 13},
+		{"every rate unit", "base62:10:5/", 12, []string{"sec", "min", "ms", "ns"}, 12, 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := analyzer.Complete(tt.input, tt.cursor)
+			var got []string
+			for _, candidate := range c.Candidates {
+				got = append(got, candidate.Value)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected candidates %v, got %v", tt.want, got)
+			}
+			if c.Start != tt.start || c.End != tt.end {
+				t.Errorf("Expected range [%d,%d), got [%d,%d)", tt.start, tt.end, c.Start, c.End)
+			}
+		})
+	}
+
+	c := analyzer.Complete("sn:10:5/sec", 2)
+	if got, cursor := c.Apply("sn:10:5/sec", 0); got != "snowflake:10:5/sec" || cursor != 9 {
+		t.Errorf("Expected snowflake:10:5/sec with the cursor at 9, got %q, %d", got, cursor)
+	}
+}
+
+func TestCollisionAnalyzerVolatileKeys(t *testing.T) {
+	analyzer := NewCollisionAnalyzer()
+
+	result, err := analyzer.ExecuteResult("base64:10:1000/sec")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, key := range feature.VolatileKeysOf(analyzer, "base64:10:1000/sec") {
+		if _, ok := result.Field(key); !ok {
+			t.Errorf("Volatile key %q is not a field of the result", key)
+		}
+	}
+	if _, ok := result.Field("sim_collisions"); !ok {
+		t.Error("Expected the simulated collisions to be volatile")
+	}
+}
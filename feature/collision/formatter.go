@@ -1,52 +1,47 @@
 package collision
 
 import (
+	"bhelper/feature"
 	"fmt"
 	"math/big"
-	"strings"
 	"time"
 )
 
 func FormatResult(format string, length int, rate int64, mathResult *MathResult, simResult *SimResult) string {
-	var sb strings.Builder
-
-	sb.WriteString(fmt.Sprintf("Collision Analysis: %s length %d\n\n", format, length))
+	return BuildResult(format, length, rate, mathResult, simResult).String()
+}
 
-	sb.WriteString("Mathematical Results:\n")
-	sb.WriteString(fmt.Sprintf("  - Total ID Space: %s\n", formatNumber(mathResult.TotalSpace)))
-	sb.WriteString(fmt.Sprintf("  - Generation Rate: %d/sec\n", rate))
-	sb.WriteString(fmt.Sprintf("  - Collision Probability (1 sec): %s\n", formatProbability(mathResult.Probability)))
-	sb.WriteString(fmt.Sprintf("  - Expected Collisions (1 sec): %d\n\n", mathResult.ExpectedCollisions))
+func BuildResult(format string, length int, rate int64, mathResult *MathResult, simResult *SimResult) *feature.Result {
+	result := feature.NewResult(fmt.Sprintf("Collision Analysis: %s length %d", format, length))
 
-	sb.WriteString("  Time to Collision:\n")
-	sb.WriteString(fmt.Sprintf("  - 50%% probability: %s\n", formatDuration(mathResult.TimeToCollision.P50)))
-	sb.WriteString(fmt.Sprintf("  - 1%% probability: %s\n", formatDuration(mathResult.TimeToCollision.P01)))
-	sb.WriteString(fmt.Sprintf("  - 0.1%% probability: %s\n\n", formatDuration(mathResult.TimeToCollision.P001)))
+	probability, _ := mathResult.Probability.Float64()
+	result.AddSection("Mathematical Results").
+		AddFormatted("total_space", "Total ID Space", mathResult.TotalSpace, formatNumber(mathResult.TotalSpace)).
+		AddFormatted("rate_per_sec", "Generation Rate", rate, fmt.Sprintf("%d/sec", rate)).
+		AddFormatted("probability", "Collision Probability (1 sec)", probability, formatProbability(mathResult.Probability)).
+		Add("expected_collisions", "Expected Collisions (1 sec)", mathResult.ExpectedCollisions)
 
-	sb.WriteString("Simulation Results:\n")
-	sb.WriteString(fmt.Sprintf("  - Collisions Found: %d\n", simResult.Collisions))
-	sb.WriteString(fmt.Sprintf("  - Measured Probability: %s (%d in %d)\n",
-		formatProbabilityFloat(simResult.Probability),
-		int(simResult.Probability*10000), 10000))
+	ttc := mathResult.TimeToCollision
+	result.AddSection("Time to Collision").
+		AddFormatted("p50_seconds", "50% probability", ttc.P50.Seconds(), formatDuration(ttc.P50)).
+		AddFormatted("p01_seconds", "1% probability", ttc.P01.Seconds(), formatDuration(ttc.P01)).
+		AddFormatted("p001_seconds", "0.1% probability", ttc.P001.Seconds(), formatDuration(ttc.P001))
 
-	mathProb := mathResult.Probability
-	simProb := big.NewFloat(simResult.Probability)
-	diff := new(big.Float).Sub(simProb, mathProb)
-	sb.WriteString(fmt.Sprintf("  - Difference: %s\n", formatProbability(diff)))
+	diff := new(big.Float).Sub(big.NewFloat(simResult.Probability), mathResult.Probability)
+	diffFloat, _ := diff.Float64()
+	result.AddSection("Simulation Results").
+		Add("sim_iterations", "Iterations", simResult.Iterations).
+		Add("sim_collisions", "Collisions Found", simResult.Collisions).
+		AddFormatted("sim_probability", "Measured Probability", simResult.Probability,
+			fmt.Sprintf("%s (%d in %d)", formatProbabilityFloat(simResult.Probability),
+				int(simResult.Probability*10000), 10000)).
+		AddFormatted("probability_difference", "Difference", diffFloat, formatProbability(diff))
 
-	return sb.String()
+	return result
 }
 
 func formatNumber(n uint64) string {
-	if n < 1000 {
-		return fmt.Sprintf("%d", n)
-	} else if n < 1000000 {
-		return fmt.Sprintf("%d,%03d", n/1000, n%1000)
-	} else if n < 1000000000 {
-		return fmt.Sprintf("%d,%03d,%03d", n/1000000, (n/1000)%1000, n%1000)
-	} else {
-		return fmt.Sprintf("%d,%03d,%03d,%03d", n/1000000000, (n/1000000)%1000, (n/1000)%1000, n%1000)
-	}
+	return feature.GroupDigits(n)
 }
 
 func formatProbability(p *big.Float) string {
@@ -54,6 +54,7 @@ func TestFormatNumber(t *testing.T) {
 		{1000, "1,000"},
 		{1000000, "1,000,000"},
 		{1000000000, "1,000,000,000"},
+		{839299365868340224, "839,299,365,868,340,224"},
 	}
 
 	for _, test := range tests {
@@ -82,3 +83,43 @@ func TestFormatDuration(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildResult(t *testing.T) {
+	mathResult := &MathResult{
+		TotalSpace:         1000,
+		TotalIDs:           100,
+		Probability:        big.NewFloat(0.005),
+		ExpectedCollisions: 1,
+		TimeToCollision: &TimeResult{
+			P50:  time.Hour,
+			P01:  time.Minute,
+			P001: time.Second,
+		},
+	}
+
+	simResult := &SimResult{
+		Collisions:  2,
+		Iterations:  1000,
+		Probability: 0.002,
+	}
+
+	result := BuildResult("base64", 8, 1000, mathResult, simResult)
+
+	fields := make(map[string]any)
+	for _, f := range result.Fields() {
+		fields[f.Key] = f.Value
+	}
+
+	if fields["total_space"] != uint64(1000) {
+		t.Errorf("Expected total_space 1000, got %v", fields["total_space"])
+	}
+	if fields["probability"] != 0.005 {
+		t.Errorf("Expected raw probability 0.005, got %v", fields["probability"])
+	}
+	if fields["p50_seconds"] != 3600.0 {
+		t.Errorf("Expected p50_seconds 3600, got %v", fields["p50_seconds"])
+	}
+	if fields["sim_collisions"] != 2 {
+		t.Errorf("Expected sim_collisions 2, got %v", fields["sim_collisions"])
+	}
+}
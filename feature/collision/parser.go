@@ -1,6 +1,7 @@
 package collision
 
 import (
+	"bhelper/feature"
 	"errors"
 	"fmt"
 	"strconv"
@@ -15,26 +16,43 @@ type Config struct {
 	RateUnit string
 }
 
+// ParseInput parses "format:length:rate/unit". Errors are
+// *feature.InputError values pointing at the offending part.
 func ParseInput(input string) (*Config, error) {
-	parts := strings.Split(input, ":")
-	if len(parts) != 3 {
-		return nil, errors.New("invalid format: expected 'format:length:rate/unit'")
+	parts, offsets := splitWithOffsets(input, ":")
+	if len(parts) < 3 {
+		missing := []string{"format", "length", "rate/unit"}[len(parts):]
+		return nil, feature.NewInputError(input, len(input), len(input),
+			"invalid format: expected 'format:length:rate/unit'",
+			"add :"+strings.Join(missing, ":"))
+	}
+	if len(parts) > 3 {
+		return nil, feature.NewInputError(input, offsets[3]-1, len(input),
+			"invalid format: expected 'format:length:rate/unit'",
+			"remove the extra ':' parts")
 	}
 
 	format := parts[0]
 	length, err := strconv.Atoi(parts[1])
 	if err != nil {
-		return nil, fmt.Errorf("invalid length: %v", err)
+		return nil, feature.NewInputError(input, offsets[1], offsets[1]+len(parts[1]),
+			fmt.Sprintf("invalid length: %v", numError(err)),
+			"use a whole number of characters, e.g. 10")
 	}
 
-	rateParts := strings.Split(parts[2], "/")
+	rateParts, rateOffsets := splitWithOffsets(parts[2], "/")
 	if len(rateParts) != 2 {
-		return nil, errors.New("invalid rate format: expected 'rate/unit'")
+		return nil, feature.NewInputError(input, offsets[2], offsets[2]+len(parts[2]),
+			"invalid rate format: expected 'rate/unit'",
+			"write the rate with its unit, e.g. 1000/sec")
 	}
 
 	rate, err := strconv.ParseInt(rateParts[0], 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("invalid rate: %v", err)
+		start := offsets[2] + rateOffsets[0]
+		return nil, feature.NewInputError(input, start, start+len(rateParts[0]),
+			fmt.Sprintf("invalid rate: %v", numError(err)),
+			"use a whole number of IDs, e.g. 1000")
 	}
 	rateUnit := rateParts[1]
 
@@ -46,6 +64,27 @@ func ParseInput(input string) (*Config, error) {
 	}, nil
 }
 
+// splitWithOffsets splits s like strings.Split and returns the byte offset
+// of each part
+func splitWithOffsets(s, sep string) ([]string, []int) {
+	parts := strings.Split(s, sep)
+	offsets := make([]int, len(parts))
+	at := 0
+	for i, p := range parts {
+		offsets[i] = at
+		at += len(p) + len(sep)
+	}
+	return parts, offsets
+}
+
+// numError drops the function name strconv puts in front of its errors
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return fmt.Errorf("%q: %w", ne.Num, ne.Err)
+	}
+	return err
+}
+
 func parseDuration(s string) (time.Duration, error) {
 	if len(s) < 2 {
 		return 0, errors.New("duration too short")
@@ -1,6 +1,7 @@
 package collision
 
 import (
+	"bhelper/feature"
 	"testing"
 )
 
@@ -109,3 +110,53 @@ func TestParseInputInvalidLength(t *testing.T) {
 		t.Error("Expected error for invalid length")
 	}
 }
+
+func TestParseInputErrorPositions(t *testing.T) {
+	analyzer := NewCollisionAnalyzer()
+
+	tests := []struct {
+		name       string
+		input      string
+		start, end int
+		suggestion string
+	}{
+		{"missing parts", "base62:10", 9, 9, "add :rate/unit"},
+		{"extra parts", "base62:10:5/sec:x", 15, 17, "remove the extra ':' parts"},
+		{"length", "base62:ten:5/sec", 7, 10, "use a whole number of characters, e.g. 10"},
+		{"rate format", "base62:10:5", 10, 11, "write the rate with its unit, e.g. 1000/sec"},
+		{"rate", "base62:10:many/sec", 10, 14, "use a whole number of IDs, e.g. 1000"},
+		{"generator", "base32:10:5/sec", 0, 6, "use one of base64, base62, snowflake"},
+		{"zero length", "base62:0:5/sec", 7, 8, "use a length of at least 1"},
+		{"rate unit", "base62:10:5/hour", 12, 16, "use one of sec, min, ms, ns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := analyzer.Validate(tt.input)
+			ie, ok := feature.AsInputError(err)
+			if !ok {
+				t.Fatalf("Expected an input error, got %v", err)
+			}
+			if ie.Start != tt.start || ie.End != tt.end || ie.Suggestion != tt.suggestion {
+				t.Errorf("Expected [%d,%d) %q, got [%d,%d) %q", tt.start, tt.end, tt.suggestion, ie.Start, ie.End, ie.Suggestion)
+			}
+		})
+	}
+
+	if err := analyzer.Validate("base62:10:5000/sec"); err != nil {
+		t.Errorf("Expected valid input, got %v", err)
+	}
+}
+
+func TestParseInputErrorColumn(t *testing.T) {
+	_, err := ParseInput("base62:ten:5/sec")
+	if err == nil || err.Error() != `invalid length: "ten": invalid syntax (column 8)` {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	ie, _ := feature.AsInputError(err)
+	line, marker := ie.Context()
+	if line != "base62:ten:5/sec" || marker != "       ^^^" {
+		t.Errorf("Unexpected context %q / %q", line, marker)
+	}
+}
@@ -1,5 +1,13 @@
 package collision
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDuplicateGenerator is returned when registering a name twice
+var ErrDuplicateGenerator = errors.New("generator already registered")
+
 type GeneratorRegistry struct {
 	generators map[string]IDGenerator
 	order      []string
@@ -12,10 +20,40 @@ func NewGeneratorRegistry() *GeneratorRegistry {
 	}
 }
 
-func (r *GeneratorRegistry) Register(gen IDGenerator) {
+// Register adds a generator, failing if its name is already taken
+func (r *GeneratorRegistry) Register(gen IDGenerator) error {
 	name := gen.Name()
+	if _, ok := r.generators[name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateGenerator, name)
+	}
 	r.generators[name] = gen
 	r.order = append(r.order, name)
+	return nil
+}
+
+// Replace registers a generator, taking the place of any generator with
+// the same name
+func (r *GeneratorRegistry) Replace(gen IDGenerator) {
+	name := gen.Name()
+	if _, ok := r.generators[name]; !ok {
+		r.order = append(r.order, name)
+	}
+	r.generators[name] = gen
+}
+
+// Unregister removes a generator and reports whether it was registered
+func (r *GeneratorRegistry) Unregister(name string) bool {
+	if _, ok := r.generators[name]; !ok {
+		return false
+	}
+	delete(r.generators, name)
+	for i, n := range r.order {
+		if n == name {
+			r.order = append(r.order[:i:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return true
 }
 
 func (r *GeneratorRegistry) Get(name string) (IDGenerator, bool) {
@@ -32,5 +70,5 @@ func (r *GeneratorRegistry) List() []IDGenerator {
 }
 
 func (r *GeneratorRegistry) Names() []string {
-	return r.order
+	return append([]string(nil), r.order...)
 }
@@ -1,6 +1,9 @@
 package collision
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestNewRegistry(t *testing.T) {
 	reg := NewGeneratorRegistry()
@@ -62,3 +65,69 @@ func TestRegistryNames(t *testing.T) {
 		t.Errorf("Expected 'base64', got '%s'", names[0])
 	}
 }
+
+func TestRegistryRegisterDuplicate(t *testing.T) {
+	reg := NewGeneratorRegistry()
+	gen1, _ := NewBase64Generator(8)
+	gen2, _ := NewBase64Generator(12)
+
+	if err := reg.Register(gen1); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	err := reg.Register(gen2)
+	if !errors.Is(err, ErrDuplicateGenerator) {
+		t.Errorf("Expected ErrDuplicateGenerator, got %v", err)
+	}
+
+	if names := reg.Names(); len(names) != 1 {
+		t.Errorf("Expected a single name after duplicate, got %v", names)
+	}
+	if got, _ := reg.Get("base64"); got != gen1 {
+		t.Error("Expected the first generator to be kept")
+	}
+}
+
+func TestRegistryReplace(t *testing.T) {
+	reg := NewGeneratorRegistry()
+	gen1, _ := NewBase64Generator(8)
+	gen2, _ := NewBase62Generator(10)
+	gen3, _ := NewBase64Generator(12)
+	reg.Register(gen1)
+	reg.Register(gen2)
+
+	reg.Replace(gen3)
+
+	names := reg.Names()
+	if len(names) != 2 || names[0] != "base64" {
+		t.Errorf("Expected replaced generator to keep its position, got %v", names)
+	}
+	if got, _ := reg.Get("base64"); got != gen3 {
+		t.Error("Expected the generator to be replaced")
+	}
+}
+
+func TestRegistryUnregister(t *testing.T) {
+	reg := NewGeneratorRegistry()
+	gen1, _ := NewBase64Generator(8)
+	gen2, _ := NewBase62Generator(10)
+	reg.Register(gen1)
+	reg.Register(gen2)
+
+	if !reg.Unregister("base64") {
+		t.Error("Expected base64 to be unregistered")
+	}
+	if reg.Unregister("base64") {
+		t.Error("Expected second unregister to report false")
+	}
+
+	if _, ok := reg.Get("base64"); ok {
+		t.Error("Expected base64 to be gone")
+	}
+	if names := reg.Names(); len(names) != 1 || names[0] != "base62" {
+		t.Errorf("Expected only base62, got %v", names)
+	}
+
+	if err := reg.Register(gen1); err != nil {
+		t.Errorf("Expected to register again after unregister, got %v", err)
+	}
+}
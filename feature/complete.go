@@ -0,0 +1,86 @@
+package feature
+
+import (
+	"strings"
+)
+
+// Completion is a candidate for the part of the input being typed
+type Completion struct {
+	Value       string
+	Description string
+}
+
+// Completions are the candidates that can replace input[Start:End], the
+// byte range of the word around the cursor
+type Completions struct {
+	Start      int
+	End        int
+	Candidates []Completion
+}
+
+// CompletingFeature is implemented by features that can suggest how to
+// continue an input. Complete must be cheap, it runs on every keystroke.
+type CompletingFeature interface {
+	// Complete returns the candidates for the word at the cursor, a byte
+	// offset into input
+	Complete(input string, cursor int) Completions
+}
+
+// WarmingFeature is implemented by completing features whose candidates
+// are slow to gather the first time, such as from disk
+type WarmingFeature interface {
+	// Warm gathers the candidates ahead of the first completion
+	Warm()
+}
+
+// Warm prepares the completions of a feature that needs it
+func Warm(f Feature) {
+	if w, ok := f.(WarmingFeature); ok {
+		w.Warm()
+	}
+}
+
+// Complete returns the completions of a feature at the cursor. Features
+// without completion never have candidates.
+func Complete(f Feature, input string, cursor int) Completions {
+	cursor = min(max(cursor, 0), len(input))
+	cf, ok := f.(CompletingFeature)
+	if !ok {
+		return Completions{Start: cursor, End: cursor}
+	}
+
+	c := cf.Complete(input, cursor)
+	c.Start = min(max(c.Start, 0), len(input))
+	c.End = min(max(c.End, c.Start), len(input))
+	return c
+}
+
+// Apply replaces the completed word with candidate i, returning the new
+// input and the cursor position right after the inserted value
+func (c Completions) Apply(input string, i int) (string, int) {
+	value := c.Candidates[i].Value
+	return input[:c.Start] + value + input[c.End:], c.Start + len(value)
+}
+
+// Settled reports whether there is nothing left to complete, either
+// because there are no candidates or the word is the only candidate
+func (c Completions) Settled(input string) bool {
+	switch len(c.Candidates) {
+	case 0:
+		return true
+	case 1:
+		return c.Candidates[0].Value == input[c.Start:c.End]
+	}
+	return false
+}
+
+// MatchPrefix returns the candidates starting with prefix, ignoring case
+func MatchPrefix(prefix string, candidates []Completion) []Completion {
+	var matches []Completion
+	for _, c := range candidates {
+		if len(c.Value) >= len(prefix) && strings.EqualFold(c.Value[:len(prefix)], prefix) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
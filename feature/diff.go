@@ -0,0 +1,36 @@
+package feature
+
+// FieldChange is a field whose raw value differs between two results
+type FieldChange struct {
+	Key   string
+	Label string
+	// Old and New are nil when the field is missing from that result
+	Old *Field
+	New *Field
+}
+
+// DiffFields compares the fields of two results by key and returns the
+// changed ones, in the order of old followed by the fields only in new.
+// Values are compared in their raw text form, as in pipelines.
+func DiffFields(old, new *Result) []FieldChange {
+	var changes []FieldChange
+	seen := make(map[string]bool)
+
+	for _, o := range old.Fields() {
+		seen[o.Key] = true
+		n, ok := new.Field(o.Key)
+		switch {
+		case !ok:
+			changes = append(changes, FieldChange{Key: o.Key, Label: o.Label, Old: &o})
+		case FormatValue(o.Value) != FormatValue(n.Value):
+			changes = append(changes, FieldChange{Key: o.Key, Label: o.Label, Old: &o, New: &n})
+		}
+	}
+
+	for _, n := range new.Fields() {
+		if !seen[n.Key] {
+			changes = append(changes, FieldChange{Key: n.Key, Label: n.Label, New: &n})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,59 @@
+package feature
+
+import "testing"
+
+func TestDiffFields(t *testing.T) {
+	old := NewResult("")
+	old.AddSection("Sizes").
+		Add("same", "Same", 90).
+		Add("changed", "Changed", "base62").
+		Add("removed", "Removed", true)
+	old.AddSection("Rates").Add("rate", "Rate", 1.5)
+
+	new := NewResult("")
+	new.AddSection("Rates").
+		Add("added", "Added", "new").
+		Add("rate", "Rate", 1.5)
+	new.AddSection("Sizes").
+		// Raw values are compared as text, so the type may change
+		Add("same", "Same", 90.0).
+		AddFormatted("changed", "Changed", "base64", "Base64")
+
+	changes := DiffFields(old, new)
+
+	want := []struct {
+		key      string
+		old, new any
+	}{
+		{"changed", "base62", "base64"},
+		{"removed", true, nil},
+		{"added", nil, "new"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Key != w.key || c.Label == "" {
+			t.Errorf("Change %d: expected %s with its label, got %+v", i, w.key, c)
+		}
+		if (c.Old == nil) != (w.old == nil) || (c.Old != nil && c.Old.Value != w.old) {
+			t.Errorf("Change %d: expected old %v, got %+v", i, w.old, c.Old)
+		}
+		if (c.New == nil) != (w.new == nil) || (c.New != nil && c.New.Value != w.new) {
+			t.Errorf("Change %d: expected new %v, got %+v", i, w.new, c.New)
+		}
+	}
+
+	// Each change points at its own field
+	if changes[0].Old.Key != "changed" || changes[1].Old.Key != "removed" {
+		t.Errorf("Expected distinct old fields, got %+v and %+v", changes[0].Old, changes[1].Old)
+	}
+
+	if changes := DiffFields(old, old); len(changes) != 0 {
+		t.Errorf("Expected no changes between equal results, got %+v", changes)
+	}
+	if changes := DiffFields(TextResult("a"), TextResult("b")); len(changes) != 0 {
+		t.Errorf("Expected text results to have no fields to compare, got %+v", changes)
+	}
+}
@@ -1,5 +1,12 @@
 package feature
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // Feature represents a plugin that can be executed by the CLI
 type Feature interface {
 	// ID returns unique identifier for the feature
@@ -21,12 +28,87 @@ type Feature interface {
 	Examples() []Example
 }
 
+// Categorized is implemented by features that belong to a category and
+// carry extra search tags
+type Categorized interface {
+	// Category returns the heading the feature is grouped under
+	Category() string
+
+	// Tags returns additional keywords used when searching
+	Tags() []string
+}
+
+// DefaultCategory is used for features that do not implement Categorized
+const DefaultCategory = "Other"
+
+// CategoryOf returns the category of a feature
+func CategoryOf(f Feature) string {
+	if c, ok := f.(Categorized); ok && c.Category() != "" {
+		return c.Category()
+	}
+	return DefaultCategory
+}
+
+// TagsOf returns the search tags of a feature
+func TagsOf(f Feature) []string {
+	if c, ok := f.(Categorized); ok {
+		return c.Tags()
+	}
+	return nil
+}
+
+// LiveFeature is implemented by features that are cheap enough to be
+// re-evaluated while the user types
+type LiveFeature interface {
+	// Live reports whether the feature supports live evaluation
+	Live() bool
+}
+
+// IsLive reports whether a feature supports live evaluation
+func IsLive(f Feature) bool {
+	l, ok := f.(LiveFeature)
+	return ok && l.Live()
+}
+
+// MultilineFeature is implemented by features that take documents such as
+// JSON, certificates or log excerpts rather than a single line
+type MultilineFeature interface {
+	// Multiline reports whether the input may span several lines
+	Multiline() bool
+}
+
+// IsMultiline reports whether a feature takes multi-line input
+func IsMultiline(f Feature) bool {
+	m, ok := f.(MultilineFeature)
+	return ok && m.Multiline()
+}
+
+// VolatileFeature is implemented by features whose results hold values that
+// change from run to run, such as the current time or simulated figures
+type VolatileFeature interface {
+	// VolatileKeys returns the keys of the fields that a run of input is
+	// not expected to repeat
+	VolatileKeys(input string) []string
+}
+
+// VolatileKeysOf returns the fields of a run of input that may change when
+// it runs again, nil for most features
+func VolatileKeysOf(f Feature, input string) []string {
+	if v, ok := f.(VolatileFeature); ok {
+		return v.VolatileKeys(input)
+	}
+	return nil
+}
+
 // Example represents a usage example
 type Example struct {
 	Input       string
 	Description string
 }
 
+// ErrDuplicateID is returned when registering a feature whose ID is taken
+var ErrDuplicateID = errors.New("duplicate feature ID")
+
 // FeatureRegistry manages all available features
 type FeatureRegistry struct {
 	features map[string]Feature
@@ -41,10 +123,44 @@ func NewFeatureRegistry() *FeatureRegistry {
 	}
 }
 
-// Register adds a feature to the registry
-func (r *FeatureRegistry) Register(f Feature) {
-	r.features[f.ID()] = f
-	r.order = append(r.order, f.ID())
+// Register adds a feature to the registry, failing if its ID is empty or
+// already registered
+func (r *FeatureRegistry) Register(f Feature) error {
+	id := f.ID()
+	if id == "" {
+		return fmt.Errorf("feature %q has an empty ID", f.Name())
+	}
+	if _, ok := r.features[id]; ok {
+		return fmt.Errorf("%w: %q is already registered", ErrDuplicateID, id)
+	}
+	r.features[id] = f
+	r.order = append(r.order, id)
+	return nil
+}
+
+// Replace registers a feature, taking the place of any feature with the
+// same ID while keeping its position
+func (r *FeatureRegistry) Replace(f Feature) {
+	id := f.ID()
+	if _, ok := r.features[id]; !ok {
+		r.order = append(r.order, id)
+	}
+	r.features[id] = f
+}
+
+// Unregister removes a feature and reports whether it was registered
+func (r *FeatureRegistry) Unregister(id string) bool {
+	if _, ok := r.features[id]; !ok {
+		return false
+	}
+	delete(r.features, id)
+	for i, registered := range r.order {
+		if registered == id {
+			r.order = append(r.order[:i:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return true
 }
 
 // Get retrieves a feature by ID
@@ -61,3 +177,52 @@ func (r *FeatureRegistry) List() []Feature {
 	}
 	return result
 }
+
+// SortOrder is the order features are listed in
+type SortOrder string
+
+const (
+	SortRegistration SortOrder = "registration"
+	SortAlphabetical SortOrder = "alphabetical"
+	SortMostUsed     SortOrder = "most-used"
+)
+
+// SortOrders lists all supported sort orders
+var SortOrders = []SortOrder{SortRegistration, SortAlphabetical, SortMostUsed}
+
+// ParseSortOrder converts a sort order name into a SortOrder
+func ParseSortOrder(name string) (SortOrder, error) {
+	for _, o := range SortOrders {
+		if string(o) == name {
+			return o, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort order %q (expected registration, alphabetical or most-used)", name)
+}
+
+// Sorted returns all features in the given order. Alphabetical order
+// compares names case-insensitively. Most-used order ranks features by
+// uses, which may be nil when no usage is known. Ties keep registration
+// order.
+func (r *FeatureRegistry) Sorted(order SortOrder, uses func(id string) int) []Feature {
+	features := r.List()
+
+	switch order {
+	case SortAlphabetical:
+		sort.SliceStable(features, func(i, j int) bool {
+			return strings.ToLower(features[i].Name()) < strings.ToLower(features[j].Name())
+		})
+	case SortMostUsed:
+		if uses == nil {
+			break
+		}
+		counts := make(map[string]int, len(features))
+		for _, f := range features {
+			counts[f.ID()] = uses(f.ID())
+		}
+		sort.SliceStable(features, func(i, j int) bool {
+			return counts[features[i].ID()] > counts[features[j].ID()]
+		})
+	}
+	return features
+}
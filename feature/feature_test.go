@@ -0,0 +1,166 @@
+package feature
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stub is a minimal feature exposing the given outputs. Its name is the
+// upper-cased ID unless set.
+type stub struct {
+	id      string
+	name    string
+	outputs []Output
+}
+
+func (s stub) ID() string { return s.id }
+func (s stub) Name() string {
+	if s.name != "" {
+		return s.name
+	}
+	return strings.ToUpper(s.id)
+}
+func (s stub) Description() string                  { return "Test feature " + s.id }
+func (s stub) Help() string                         { return "" }
+func (s stub) Examples() []Example                  { return nil }
+func (s stub) Execute(input string) (string, error) { return input, nil }
+func (s stub) Outputs() []Output                    { return s.outputs }
+
+// ids returns the IDs of features in order
+func ids(features []Feature) []string {
+	result := make([]string, len(features))
+	for i, f := range features {
+		result[i] = f.ID()
+	}
+	return result
+}
+
+func newTestRegistry(t *testing.T, features ...Feature) *FeatureRegistry {
+	t.Helper()
+
+	r := NewFeatureRegistry()
+	for _, f := range features {
+		if err := r.Register(f); err != nil {
+			t.Fatalf("Register(%s) failed: %v", f.ID(), err)
+		}
+	}
+	return r
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	first := stub{id: "time", name: "Time Converter"}
+	r := newTestRegistry(t, first)
+
+	err := r.Register(stub{id: "time", name: "Other Time"})
+	if !errors.Is(err, ErrDuplicateID) {
+		t.Fatalf("Expected ErrDuplicateID, got %v", err)
+	}
+	if f, _ := r.Get("time"); f.Name() != "Time Converter" {
+		t.Errorf("Expected the first feature to stay registered, got %s", f.Name())
+	}
+	if got := ids(r.List()); !slices.Equal(got, []string{"time"}) {
+		t.Errorf("Expected a single entry, got %v", got)
+	}
+}
+
+func TestRegisterRejectsEmptyID(t *testing.T) {
+	r := NewFeatureRegistry()
+	if err := r.Register(stub{name: "Nameless"}); err == nil || !strings.Contains(err.Error(), "Nameless") {
+		t.Errorf("Expected error naming the feature, got %v", err)
+	}
+	if len(r.List()) != 0 {
+		t.Error("Expected nothing registered")
+	}
+}
+
+func TestReplaceKeepsPosition(t *testing.T) {
+	r := newTestRegistry(t, stub{id: "a"}, stub{id: "b"}, stub{id: "c"})
+
+	r.Replace(stub{id: "b", name: "Replaced"})
+	if got := ids(r.List()); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("Expected the replaced feature in place, got %v", got)
+	}
+	if f, _ := r.Get("b"); f.Name() != "Replaced" {
+		t.Errorf("Expected the new feature, got %s", f.Name())
+	}
+
+	r.Replace(stub{id: "d"})
+	if got := ids(r.List()); !slices.Equal(got, []string{"a", "b", "c", "d"}) {
+		t.Errorf("Expected a new ID to be appended, got %v", got)
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	r := newTestRegistry(t, stub{id: "a"}, stub{id: "b"}, stub{id: "c"})
+
+	if !r.Unregister("b") {
+		t.Fatal("Expected b to be unregistered")
+	}
+	if r.Unregister("b") {
+		t.Error("Expected a second unregister to report false")
+	}
+	if _, ok := r.Get("b"); ok {
+		t.Error("Expected b to be gone")
+	}
+	if got := ids(r.List()); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("Expected the others in order, got %v", got)
+	}
+
+	// The ID can be registered again and goes last
+	if err := r.Register(stub{id: "b"}); err != nil {
+		t.Fatalf("Register after unregister failed: %v", err)
+	}
+	if got := ids(r.List()); !slices.Equal(got, []string{"a", "c", "b"}) {
+		t.Errorf("Expected b appended, got %v", got)
+	}
+}
+
+func TestSorted(t *testing.T) {
+	r := newTestRegistry(t,
+		stub{id: "zulu", name: "zulu"},
+		stub{id: "alpha", name: "Alpha"},
+		stub{id: "bravo", name: "bravo"},
+		stub{id: "alpha2", name: "ALPHA"},
+	)
+	uses := map[string]int{"bravo": 5, "zulu": 2, "alpha2": 2}
+
+	tests := []struct {
+		name  string
+		order SortOrder
+		uses  func(string) int
+		want  []string
+	}{
+		{"registration", SortRegistration, nil, []string{"zulu", "alpha", "bravo", "alpha2"}},
+		{"unknown order", SortOrder("random"), nil, []string{"zulu", "alpha", "bravo", "alpha2"}},
+		// Names equal but for case keep registration order
+		{"alphabetical", SortAlphabetical, nil, []string{"alpha", "alpha2", "bravo", "zulu"}},
+		// Equal counts keep registration order, unused features go last
+		{"most used", SortMostUsed, func(id string) int { return uses[id] }, []string{"bravo", "zulu", "alpha2", "alpha"}},
+		{"most used without usage", SortMostUsed, nil, []string{"zulu", "alpha", "bravo", "alpha2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(r.Sorted(tt.order, tt.uses)); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if got := ids(r.List()); !slices.Equal(got, []string{"zulu", "alpha", "bravo", "alpha2"}) {
+		t.Errorf("Expected sorting to leave the registry order alone, got %v", got)
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	for _, o := range SortOrders {
+		if got, err := ParseSortOrder(string(o)); err != nil || got != o {
+			t.Errorf("Expected %s, got %q (%v)", o, got, err)
+		}
+	}
+	if _, err := ParseSortOrder("newest"); err == nil {
+		t.Error("Expected error for unknown order")
+	}
+}
@@ -0,0 +1,208 @@
+package feature
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format for feature results
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatCSV  Format = "csv"
+)
+
+// Formats lists all supported output formats
+var Formats = []Format{FormatText, FormatJSON, FormatYAML, FormatCSV}
+
+// ParseFormat converts a format name into a Format
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (expected text, json, yaml or csv)", name)
+}
+
+// Next returns the format following f in Formats, wrapping around
+func (f Format) Next() Format {
+	for i, candidate := range Formats {
+		if candidate == f {
+			return Formats[(i+1)%len(Formats)]
+		}
+	}
+	return FormatText
+}
+
+// Document is the machine-readable form of a feature result. Values holds
+// every field keyed by its stable Key; Sections keeps the full ordered layout.
+type Document struct {
+	Feature  string         `json:"feature" yaml:"feature"`
+	Input    string         `json:"input" yaml:"input"`
+	Title    string         `json:"title,omitempty" yaml:"title,omitempty"`
+	Values   map[string]any `json:"values" yaml:"values"`
+	Sections []*Section     `json:"sections" yaml:"sections"`
+}
+
+// NewDocument wraps a result with the feature ID and input that produced
+// it. NaN and infinite values become text, which every format can encode.
+func NewDocument(featureID, input string, r *Result) *Document {
+	values := make(map[string]any)
+	sections := make([]*Section, len(r.Sections))
+	for i, s := range r.Sections {
+		section := *s
+		if s.Fields != nil {
+			section.Fields = make([]Field, len(s.Fields))
+		}
+		for j, f := range s.Fields {
+			f.Value = finite(f.Value)
+			section.Fields[j] = f
+			values[f.Key] = f.Value
+		}
+		sections[i] = &section
+	}
+
+	return &Document{
+		Feature:  featureID,
+		Input:    input,
+		Title:    r.Title,
+		Values:   values,
+		Sections: sections,
+	}
+}
+
+// finite replaces a NaN or infinite float, which JSON cannot hold, with its
+// text form
+func finite(v any) any {
+	switch f := v.(type) {
+	case float64:
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return FormatValue(f)
+		}
+	case float32:
+		if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+			return FormatValue(f)
+		}
+	}
+	return v
+}
+
+// Result returns the result the document was built from
+func (d *Document) Result() *Result {
+	return &Result{Title: d.Title, Sections: d.Sections}
+}
+
+// Encode writes the document to w in the given format
+func Encode(w io.Writer, doc *Document, format Format) error {
+	switch format {
+	case FormatText:
+		_, err := io.WriteString(w, doc.Result().String())
+		return err
+
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+
+	case FormatCSV:
+		return encodeCSV(w, doc)
+
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// csvHeader is the header row of CSV output
+var csvHeader = []string{"feature", "input", "section", "key", "label", "value", "display"}
+
+// encodeCSV writes one row per field. Table cells become rows keyed by
+// column[index], free-form text becomes a single row keyed "text".
+func encodeCSV(w io.Writer, doc *Document) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, s := range doc.Sections {
+		for _, f := range s.Fields {
+			row := []string{doc.Feature, doc.Input, s.Title, f.Key, f.Label, FormatValue(f.Value), f.Display}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+
+		if s.Table != nil {
+			for i, cells := range s.Table.Rows {
+				for j, cell := range cells {
+					if j >= len(s.Table.Columns) {
+						break
+					}
+					key := fmt.Sprintf("%s[%d]", s.Table.Columns[j], i)
+					row := []string{doc.Feature, doc.Input, s.Title, key, s.Table.Columns[j], cell, cell}
+					if err := cw.Write(row); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if s.Text != "" {
+			row := []string{doc.Feature, doc.Input, s.Title, "text", "Text", s.Text, s.Text}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// GroupDigits renders n with its digits grouped by thousands, e.g. 1,234,567
+func GroupDigits(n uint64) string {
+	digits := strconv.FormatUint(n, 10)
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// FormatValue renders a raw field value without losing precision
+func FormatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
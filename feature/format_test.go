@@ -0,0 +1,201 @@
+package feature
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func sampleDocument() *Document {
+	result := NewResult("Sample")
+	result.AddSection("Sizes").
+		AddFormatted("space", "ID Space", uint64(839299365868340224), GroupDigits(839299365868340224)).
+		Add("ratio", "Ratio", 1e-30)
+	result.AddSection("Generators").SetTable([]string{"Name", "Bits"}, [][]string{{"uuid", "122"}, {"ulid", "80", "extra"}})
+	result.AddSection("Notes").Text = "Line one, \"quoted\"\nLine two"
+	return NewDocument("sample", "in,put", result)
+}
+
+func TestGroupDigits(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{123456, "123,456"},
+		{1234567, "1,234,567"},
+		{1000000000000, "1,000,000,000,000"},
+		// Regression: numbers past a billion were only grouped once
+		{839299365868340224, "839,299,365,868,340,224"},
+		{math.MaxUint64, "18,446,744,073,709,551,615"},
+	}
+
+	for _, tt := range tests {
+		if got := GroupDigits(tt.n); got != tt.want {
+			t.Errorf("GroupDigits(%d): expected %s, got %s", tt.n, tt.want, got)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"nil", nil, ""},
+		{"string", "hello", "hello"},
+		{"int", 42, "42"},
+		{"large int", int64(9007199254740993), "9007199254740993"},
+		{"uint64", uint64(839299365868340224), "839299365868340224"},
+		{"float", 1.5, "1.5"},
+		{"small float", 1e-7, "0.0000001"},
+		{"large float", 1e21, "1000000000000000000000"},
+		{"float32", float32(0.25), "0.25"},
+		{"bool", true, "true"},
+		{"json number", json.Number("12345678901234567890"), "12345678901234567890"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatValue(tt.value); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		if got, err := ParseFormat(string(f)); err != nil || got != f {
+			t.Errorf("Expected %s, got %q (%v)", f, got, err)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil || !strings.Contains(err.Error(), "unknown output format") {
+		t.Errorf("Expected error for unknown format, got %v", err)
+	}
+}
+
+func TestFormatNext(t *testing.T) {
+	if FormatCSV.Next() != FormatText || FormatText.Next() != FormatJSON {
+		t.Errorf("Expected formats to cycle in order")
+	}
+	if Format("xml").Next() != FormatText {
+		t.Errorf("Expected unknown format to restart at text")
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	var b strings.Builder
+	if err := Encode(&b, sampleDocument(), FormatCSV); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	want := `feature,input,section,key,label,value,display
+sample,"in,put",Sizes,space,ID Space,839299365868340224,"839,299,365,868,340,224"
+sample,"in,put",Sizes,ratio,Ratio,0.000000000000000000000000000001,1e-30
+sample,"in,put",Generators,Name[0],Name,uuid,uuid
+sample,"in,put",Generators,Bits[0],Bits,122,122
+sample,"in,put",Generators,Name[1],Name,ulid,ulid
+sample,"in,put",Generators,Bits[1],Bits,80,80
+sample,"in,put",Notes,text,Text,"Line one, ""quoted""
+Line two","Line one, ""quoted""
+Line two"
+`
+	if b.String() != want {
+		t.Errorf("Unexpected CSV:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestEncodeYAML(t *testing.T) {
+	var b strings.Builder
+	if err := Encode(&b, sampleDocument(), FormatYAML); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	var decoded struct {
+		Feature  string         `yaml:"feature"`
+		Input    string         `yaml:"input"`
+		Title    string         `yaml:"title"`
+		Values   map[string]any `yaml:"values"`
+		Sections []*Section     `yaml:"sections"`
+	}
+	if err := yaml.Unmarshal([]byte(b.String()), &decoded); err != nil {
+		t.Fatalf("Output is not valid YAML: %v\n%s", err, b.String())
+	}
+
+	if decoded.Feature != "sample" || decoded.Input != "in,put" || decoded.Title != "Sample" {
+		t.Errorf("Unexpected header: %+v", decoded)
+	}
+	if got := FormatValue(decoded.Values["space"]); got != "839299365868340224" {
+		t.Errorf("Expected the large integer to survive, got %s", got)
+	}
+	if len(decoded.Sections) != 3 || decoded.Sections[2].Text != "Line one, \"quoted\"\nLine two" {
+		t.Errorf("Expected sections with the text kept, got %+v", decoded.Sections)
+	}
+	if !strings.HasPrefix(b.String(), "feature: sample\n") || strings.Contains(b.String(), "\t") {
+		t.Errorf("Expected two-space indented YAML, got:\n%s", b.String())
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	var b strings.Builder
+	if err := Encode(&b, sampleDocument(), FormatJSON); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(b.String()))
+	dec.UseNumber()
+	var doc Document
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if got := FormatValue(doc.Values["space"]); got != "839299365868340224" {
+		t.Errorf("Expected the large integer to survive, got %s", got)
+	}
+}
+
+func TestEncodeUnknownFormat(t *testing.T) {
+	if err := Encode(&strings.Builder{}, sampleDocument(), Format("xml")); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestEncodeNonFinite(t *testing.T) {
+	result := NewResult("")
+	result.AddSection("Hours").
+		Add("hours", "Hours", math.Inf(1)).
+		Add("minutes", "Minutes", math.Inf(-1)).
+		Add("ratio", "Ratio", math.NaN()).
+		Add("small", "Small", float32(math.Inf(1)))
+	doc := NewDocument("time", "1e308h", result)
+
+	want := map[string]string{"hours": "+Inf", "minutes": "-Inf", "ratio": "NaN", "small": "+Inf"}
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			var b strings.Builder
+			if err := Encode(&b, doc, format); err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			for _, v := range want {
+				if !strings.Contains(b.String(), v) {
+					t.Errorf("Expected %s in:\n%s", v, b.String())
+				}
+			}
+		})
+	}
+
+	for key, v := range want {
+		if doc.Values[key] != v {
+			t.Errorf("Expected %s as %q, got %v", key, v, doc.Values[key])
+		}
+	}
+	if f := result.Fields()[0]; f.Value != math.Inf(1) || f.Display != "+Inf" {
+		t.Errorf("Expected the result to keep its value, got %+v", f)
+	}
+}
@@ -0,0 +1,196 @@
+package feature
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OptionType is the type of a feature option value
+type OptionType int
+
+const (
+	OptionString OptionType = iota
+	OptionInt
+	OptionFloat
+	OptionBool
+	OptionDuration
+)
+
+// String returns the name of the option type
+func (t OptionType) String() string {
+	switch t {
+	case OptionString:
+		return "string"
+	case OptionInt:
+		return "integer"
+	case OptionFloat:
+		return "number"
+	case OptionBool:
+		return "boolean"
+	case OptionDuration:
+		return "duration"
+	default:
+		return "unknown"
+	}
+}
+
+// OptionSpec describes an option accepted by a feature
+type OptionSpec struct {
+	Key         string
+	Type        OptionType
+	Default     any
+	Description string
+}
+
+// Configurable is implemented by features that accept per-feature options
+// from the user configuration
+type Configurable interface {
+	// OptionSpecs describes the accepted options and their defaults
+	OptionSpecs() []OptionSpec
+
+	// Configure applies validated options
+	Configure(opts Options) error
+}
+
+// Options holds typed option values keyed by OptionSpec.Key. Values have
+// the Go type matching their OptionType, so the accessors never fail for
+// options declared in the specs.
+type Options map[string]any
+
+// String returns a string option
+func (o Options) String(key string) string {
+	v, _ := o[key].(string)
+	return v
+}
+
+// Int returns an integer option
+func (o Options) Int(key string) int {
+	v, _ := o[key].(int)
+	return v
+}
+
+// Float returns a number option
+func (o Options) Float(key string) float64 {
+	v, _ := o[key].(float64)
+	return v
+}
+
+// Bool returns a boolean option
+func (o Options) Bool(key string) bool {
+	v, _ := o[key].(bool)
+	return v
+}
+
+// Duration returns a duration option
+func (o Options) Duration(key string) time.Duration {
+	v, _ := o[key].(time.Duration)
+	return v
+}
+
+// ParseOptions validates raw configuration values against specs, converting
+// them to their declared types and filling in defaults
+func ParseOptions(specs []OptionSpec, raw map[string]any) (Options, error) {
+	opts := make(Options, len(specs))
+	known := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		opts[spec.Key] = spec.Default
+		known[spec.Key] = true
+	}
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("unknown option %q", key))
+		}
+	}
+
+	for _, spec := range specs {
+		value, ok := raw[spec.Key]
+		if !ok {
+			continue
+		}
+
+		converted, err := convertOption(spec.Type, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("option %q: %w", spec.Key, err))
+			continue
+		}
+		opts[spec.Key] = converted
+	}
+
+	return opts, errors.Join(errs...)
+}
+
+// convertOption converts a decoded configuration value to the Go type of t
+func convertOption(t OptionType, value any) (any, error) {
+	invalid := fmt.Errorf("expected %s, got %v", t, value)
+
+	switch t {
+	case OptionString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+
+	case OptionInt:
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) {
+				return int(v), nil
+			}
+		}
+
+	case OptionFloat:
+		switch v := value.(type) {
+		case int:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+
+	case OptionBool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+
+	case OptionDuration:
+		if s, ok := value.(string); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(s))
+			if err != nil {
+				return nil, invalid
+			}
+			return d, nil
+		}
+	}
+
+	return nil, invalid
+}
+
+// Configure validates raw options and applies them to a feature. Features
+// that are not Configurable accept no options.
+func Configure(f Feature, raw map[string]any) error {
+	c, ok := f.(Configurable)
+	if !ok {
+		if len(raw) > 0 {
+			return fmt.Errorf("feature %q does not accept options", f.ID())
+		}
+		return nil
+	}
+
+	opts, err := ParseOptions(c.OptionSpecs(), raw)
+	if err != nil {
+		return err
+	}
+	return c.Configure(opts)
+}
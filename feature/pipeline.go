@@ -0,0 +1,134 @@
+package feature
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output describes a named result field that can be passed on to another
+// feature
+type Output struct {
+	Key         string
+	Description string
+}
+
+// OutputFeature is implemented by features that expose named output fields.
+// The first output is the default one used when a pipeline picks none.
+type OutputFeature interface {
+	// Outputs returns the fields other features can be fed from
+	Outputs() []Output
+}
+
+// OutputsOf returns the named outputs of a feature
+func OutputsOf(f Feature) []Output {
+	if o, ok := f.(OutputFeature); ok {
+		return o.Outputs()
+	}
+	return nil
+}
+
+// Field returns the field with the given key
+func (r *Result) Field(key string) (Field, bool) {
+	for _, f := range r.Fields() {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// SelectOutput returns the raw value of a result field as text, to be used
+// as input of another feature. An empty key selects the default output of
+// f, or the only field of the result.
+func SelectOutput(f Feature, r *Result, key string) (string, error) {
+	if key == "" {
+		if outputs := OutputsOf(f); len(outputs) > 0 {
+			key = outputs[0].Key
+		} else if fields := r.Fields(); len(fields) == 1 {
+			key = fields[0].Key
+		} else {
+			return "", fmt.Errorf("%s has no default output, pick a field with %s.<field> (%s)", f.ID(), f.ID(), fieldKeys(r))
+		}
+	}
+
+	field, ok := r.Field(key)
+	if !ok {
+		return "", fmt.Errorf("%s has no output %q (%s)", f.ID(), key, fieldKeys(r))
+	}
+	return FormatValue(field.Value), nil
+}
+
+// fieldKeys lists the field keys of a result for error messages
+func fieldKeys(r *Result) string {
+	fields := r.Fields()
+	if len(fields) == 0 {
+		return "its result has no fields"
+	}
+
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+	return "available: " + strings.Join(keys, ", ")
+}
+
+// PipeInput marks where a piped value goes in the input of a stage
+const PipeInput = "{}"
+
+// Stage is a step of a pipeline. Field names the output passed on to the
+// next stage, Input is the input text, which for later stages may place
+// the piped value with PipeInput.
+type Stage struct {
+	FeatureID string
+	Field     string
+	Input     string
+}
+
+// ParsePipeline parses stages of the form "id[.field] [input]" separated
+// by "|". A literal "|" in an input is written as "\|".
+func ParsePipeline(spec string) ([]Stage, error) {
+	var stages []Stage
+	for i, part := range splitStages(spec) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("pipeline stage %d is empty", i+1)
+		}
+
+		head, input, _ := strings.Cut(part, " ")
+		id, field, _ := strings.Cut(head, ".")
+		stage := Stage{FeatureID: id, Field: field, Input: strings.TrimSpace(input)}
+
+		if i > 0 && stage.Input != "" && !strings.Contains(stage.Input, PipeInput) {
+			return nil, fmt.Errorf("pipeline stage %d (%s): the input must contain %s to place the piped value", i+1, id, PipeInput)
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+// splitStages splits a pipeline at unescaped "|"
+func splitStages(spec string) []string {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(spec); i++ {
+		switch {
+		case spec[i] == '\\' && i+1 < len(spec) && spec[i+1] == '|':
+			b.WriteByte('|')
+			i++
+		case spec[i] == '|':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(spec[i])
+		}
+	}
+	return append(parts, b.String())
+}
+
+// PipedInput returns the input of a later stage fed with value
+func (s Stage) PipedInput(value string) string {
+	if s.Input == "" {
+		return value
+	}
+	return strings.ReplaceAll(s.Input, PipeInput, value)
+}
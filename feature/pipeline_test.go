@@ -0,0 +1,120 @@
+package feature
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePipeline(t *testing.T) {
+	tests := []struct {
+		spec   string
+		stages []Stage
+		err    string
+	}{
+		{
+			spec:   "time 90s",
+			stages: []Stage{{FeatureID: "time", Input: "90s"}},
+		},
+		{
+			spec: "timezone.unix 16-01-2026 | time {}s | character",
+			stages: []Stage{
+				{FeatureID: "timezone", Field: "unix", Input: "16-01-2026"},
+				{FeatureID: "time", Input: "{}s"},
+				{FeatureID: "character"},
+			},
+		},
+		{
+			spec: "  character  a \\| b  |time.minutes",
+			stages: []Stage{
+				{FeatureID: "character", Input: "a | b"},
+				{FeatureID: "time", Field: "minutes"},
+			},
+		},
+		{spec: "time 90s |", err: "pipeline stage 2 is empty"},
+		{spec: " | character", err: "pipeline stage 1 is empty"},
+		{spec: "time 90s || character", err: "pipeline stage 2 is empty"},
+		{spec: "time 90s | character hello", err: "stage 2 (character): the input must contain {}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			stages, err := ParsePipeline(tt.spec)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(stages, tt.stages) {
+				t.Errorf("Expected %+v, got %+v", tt.stages, stages)
+			}
+		})
+	}
+}
+
+func TestPipedInput(t *testing.T) {
+	tests := []struct {
+		input string
+		value string
+		want  string
+	}{
+		{"", "90", "90"},
+		{"{}s", "90", "90s"},
+		{"{} and {}", "x", "x and x"},
+		{"{}", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := (Stage{Input: tt.input}).PipedInput(tt.value); got != tt.want {
+			t.Errorf("PipedInput(%q) of %q: expected %q, got %q", tt.value, tt.input, tt.want, got)
+		}
+	}
+}
+
+func TestSelectOutput(t *testing.T) {
+	result := NewResult("")
+	result.AddSection("").Add("seconds", "Seconds", 90).Add("minutes", "Minutes", 1.5)
+	single := NewResult("")
+	single.AddSection("").Add("text", "Text", "hi")
+
+	withOutputs := stub{id: "time", outputs: []Output{{Key: "minutes"}, {Key: "seconds"}}}
+	plain := stub{id: "plain"}
+
+	tests := []struct {
+		name   string
+		f      Feature
+		result *Result
+		key    string
+		want   string
+		err    string
+	}{
+		{"default output", withOutputs, result, "", "1.5", ""},
+		{"picked output", withOutputs, result, "seconds", "90", ""},
+		{"only field", plain, single, "", "hi", ""},
+		{"no default", plain, result, "", "", "plain has no default output, pick a field with plain.<field> (available: seconds, minutes)"},
+		{"unknown output", withOutputs, result, "hours", "", `time has no output "hours" (available: seconds, minutes)`},
+		{"no fields", plain, TextResult("text"), "text", "", "its result has no fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectOutput(tt.f, tt.result, tt.key)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
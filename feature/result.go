@@ -0,0 +1,191 @@
+package feature
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StructuredFeature is implemented by features that return typed results
+// instead of pre-rendered text
+type StructuredFeature interface {
+	Feature
+
+	// ExecuteResult runs the feature and returns a structured result
+	ExecuteResult(input string) (*Result, error)
+}
+
+// Result is a structured feature result made of ordered sections
+type Result struct {
+	Title    string     `json:"title,omitempty" yaml:"title,omitempty"`
+	Sections []*Section `json:"sections" yaml:"sections"`
+}
+
+// Section groups related fields, a table or free-form text under an optional heading
+type Section struct {
+	Title  string  `json:"title,omitempty" yaml:"title,omitempty"`
+	Fields []Field `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Table  *Table  `json:"table,omitempty" yaml:"table,omitempty"`
+	Text   string  `json:"text,omitempty" yaml:"text,omitempty"`
+}
+
+// Field is a single named value. Key is the stable machine-readable name,
+// Label the human-readable one and Display the formatted value.
+type Field struct {
+	Key     string `json:"key" yaml:"key"`
+	Label   string `json:"label" yaml:"label"`
+	Value   any    `json:"value" yaml:"value"`
+	Display string `json:"display" yaml:"display"`
+}
+
+// Table is a list of rows sharing the same columns
+type Table struct {
+	Columns []string   `json:"columns" yaml:"columns"`
+	Rows    [][]string `json:"rows" yaml:"rows"`
+}
+
+// NewResult creates an empty result with the given title
+func NewResult(title string) *Result {
+	return &Result{Title: title}
+}
+
+// TextResult wraps pre-rendered text in a result with a single text section
+func TextResult(text string) *Result {
+	return &Result{Sections: []*Section{{Text: text}}}
+}
+
+// AddSection appends a new section and returns it for chaining
+func (r *Result) AddSection(title string) *Section {
+	s := &Section{Title: title}
+	r.Sections = append(r.Sections, s)
+	return s
+}
+
+// Add appends a field whose display value is the default formatting of value
+func (s *Section) Add(key, label string, value any) *Section {
+	return s.AddFormatted(key, label, value, fmt.Sprint(value))
+}
+
+// AddFormatted appends a field with an explicit display value
+func (s *Section) AddFormatted(key, label string, value any, display string) *Section {
+	s.Fields = append(s.Fields, Field{Key: key, Label: label, Value: value, Display: display})
+	return s
+}
+
+// SetTable attaches a table to the section
+func (s *Section) SetTable(columns []string, rows [][]string) *Section {
+	s.Table = &Table{Columns: columns, Rows: rows}
+	return s
+}
+
+// Fields returns all fields of the result in order
+func (r *Result) Fields() []Field {
+	var fields []Field
+	for _, s := range r.Sections {
+		fields = append(fields, s.Fields...)
+	}
+	return fields
+}
+
+// String renders the result as plain text
+func (r *Result) String() string {
+	var b strings.Builder
+
+	if r.Title != "" {
+		b.WriteString(r.Title + "\n\n")
+	}
+
+	for i, s := range r.Sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		indent := ""
+		if s.Title != "" {
+			b.WriteString(s.Title + ":\n")
+			indent = "  "
+		}
+
+		width := s.labelWidth()
+		for _, f := range s.Fields {
+			b.WriteString(fmt.Sprintf("%s%-*s %s\n", indent, width+1, f.Label+":", f.Display))
+		}
+
+		if s.Table != nil {
+			b.WriteString(s.Table.String(indent))
+		}
+
+		if s.Text != "" {
+			b.WriteString(s.Text)
+			if !strings.HasSuffix(s.Text, "\n") {
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// labelWidth returns the length of the longest field label
+func (s *Section) labelWidth() int {
+	width := 0
+	for _, f := range s.Fields {
+		if len(f.Label) > width {
+			width = len(f.Label)
+		}
+	}
+	return width
+}
+
+// String renders the table as aligned plain-text columns
+func (t *Table) String(indent string) string {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = len([]rune(c))
+	}
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			if i < len(widths) && len([]rune(cell)) > widths[i] {
+				widths[i] = len([]rune(cell))
+			}
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString(indent)
+		for i, cell := range cells {
+			if i >= len(widths) {
+				break
+			}
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			if i == len(cells)-1 {
+				b.WriteString(cell)
+			} else {
+				b.WriteString(cell + strings.Repeat(" ", widths[i]-len([]rune(cell))))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(t.Columns)
+	for _, row := range t.Rows {
+		writeRow(row)
+	}
+	return b.String()
+}
+
+// ExecuteResult runs a feature and returns a structured result, wrapping the
+// text output of features that do not implement StructuredFeature
+func ExecuteResult(f Feature, input string) (*Result, error) {
+	if sf, ok := f.(StructuredFeature); ok {
+		return sf.ExecuteResult(input)
+	}
+
+	out, err := f.Execute(input)
+	if err != nil {
+		return nil, err
+	}
+	return TextResult(out), nil
+}
@@ -0,0 +1,76 @@
+package feature
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTimeout bounds executions of features that declare no timeout
+const DefaultTimeout = 30 * time.Second
+
+// ProgressFunc receives the completed fraction of a run, between 0 and 1
+type ProgressFunc func(fraction float64)
+
+// ContextFeature is implemented by long-running features that support
+// cancellation and progress reporting
+type ContextFeature interface {
+	Feature
+
+	// ExecuteContext runs the feature, stopping early when ctx is done
+	ExecuteContext(ctx context.Context, input string, progress ProgressFunc) (*Result, error)
+}
+
+// TimeoutFeature is implemented by features that need a non-default
+// timeout. Only a ContextFeature is stopped when it expires, Run gives up
+// waiting on other features but cannot stop them.
+type TimeoutFeature interface {
+	// Timeout returns the maximum duration of a single execution
+	Timeout() time.Duration
+}
+
+// TimeoutOf returns the execution timeout declared by a feature
+func TimeoutOf(f Feature) time.Duration {
+	if t, ok := f.(TimeoutFeature); ok && t.Timeout() > 0 {
+		return t.Timeout()
+	}
+	return DefaultTimeout
+}
+
+// Cancellable reports whether a run of the feature stops when its context
+// is done
+func Cancellable(f Feature) bool {
+	_, ok := f.(ContextFeature)
+	return ok
+}
+
+// Run executes a feature and returns its structured result. Features that
+// do not implement ContextFeature run in a separate goroutine so that Run
+// still returns as soon as ctx is done. That goroutine is abandoned, it
+// keeps running and using CPU until the feature returns.
+func Run(ctx context.Context, f Feature, input string, progress ProgressFunc) (*Result, error) {
+	if progress == nil {
+		progress = func(float64) {}
+	}
+
+	if cf, ok := f.(ContextFeature); ok {
+		return cf.ExecuteContext(ctx, input, progress)
+	}
+
+	type outcome struct {
+		result *Result
+		err    error
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := ExecuteResult(f, input)
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
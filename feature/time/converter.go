@@ -18,10 +18,14 @@ type ConversionResult struct {
 	Hours        float64
 }
 
+// parseInput splits a duration such as "1.5h" into its value and unit.
+// Errors are *feature.InputError values pointing at the offending part.
 func parseInput(input string) (float64, string, error) {
+	raw := input
+	lead := len(input) - len(strings.TrimLeft(input, " \t"))
 	input = strings.TrimSpace(input)
 	if input == "" {
-		return 0, "", fmt.Errorf("empty input")
+		return 0, "", feature.NewInputError(raw, 0, 0, "empty input", "type a duration such as 100ms")
 	}
 
 	input = strings.ToLower(input)
@@ -38,7 +42,8 @@ func parseInput(input string) (float64, string, error) {
 	}
 
 	if i == len(input) {
-		return 0, "", fmt.Errorf("no unit found")
+		end := lead + len(input)
+		return 0, "", feature.NewInputError(raw, end, end, "no unit found", "add a unit: "+unitList)
 	}
 
 	unit = input[i:]
@@ -46,7 +51,8 @@ func parseInput(input string) (float64, string, error) {
 
 	value, err := strconv.ParseFloat(valueStr, 64)
 	if err != nil {
-		return 0, "", fmt.Errorf("invalid number: %w", err)
+		return 0, "", feature.NewInputError(raw, lead, lead+len(strings.TrimRight(input[:i], " \t")),
+			fmt.Sprintf("invalid number: %q", valueStr), "start with a number, e.g. 1.5h")
 	}
 
 	validUnits := map[string]bool{
@@ -57,12 +63,49 @@ func parseInput(input string) (float64, string, error) {
 	}
 
 	if !validUnits[unit] {
-		return 0, "", fmt.Errorf("invalid unit: %s", unit)
+		return 0, "", feature.NewInputError(raw, lead+i, lead+len(input),
+			fmt.Sprintf("invalid unit: %s", unit), suggestUnit(unit))
 	}
 
 	return value, unit, nil
 }
 
+// unitList names the accepted units in error hints
+const unitList = "ns, us, ms, s, min or h"
+
+// unitAliases maps spelled-out units to the accepted ones
+var unitAliases = map[string]string{
+	"nsec": "ns", "nanos": "ns", "nanosecond": "ns", "nanoseconds": "ns",
+	"usec": "us", "micros": "us", "microsecond": "us", "microseconds": "us",
+	"msec": "ms", "millis": "ms", "millisecond": "ms", "milliseconds": "ms",
+	"sec": "s", "secs": "s", "second": "s", "seconds": "s",
+	"mins": "min", "minute": "min", "minutes": "min",
+	"hrs": "h", "hour": "h", "hours": "h",
+}
+
+// suggestUnit proposes a replacement for an unknown unit
+func suggestUnit(unit string) string {
+	if alias, ok := unitAliases[unit]; ok {
+		return fmt.Sprintf("did you mean %q?", alias)
+	}
+	return "use one of " + unitList
+}
+
+// unitCompletions are the units offered after a number
+var unitCompletions = []feature.Completion{
+	{Value: "ns", Description: "nanoseconds"},
+	{Value: "us", Description: "microseconds"},
+	{Value: "ms", Description: "milliseconds"},
+	{Value: "s", Description: "seconds"},
+	{Value: "min", Description: "minutes"},
+	{Value: "h", Description: "hours"},
+}
+
+// isUnitLetter reports whether c can be part of a unit
+func isUnitLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
 func convertToAllUnits(value float64, unit string) ConversionResult {
 	var seconds float64
 
@@ -109,6 +152,29 @@ func (tc *TimeConverter) Description() string {
 	return "Convert time values between units (nanosecond to hour)"
 }
 
+func (tc *TimeConverter) Category() string {
+	return "Time"
+}
+
+func (tc *TimeConverter) Tags() []string {
+	return []string{"duration", "units", "seconds"}
+}
+
+func (tc *TimeConverter) Outputs() []feature.Output {
+	return []feature.Output{
+		{Key: "seconds", Description: "Duration in seconds"},
+		{Key: "nanoseconds", Description: "Duration in nanoseconds"},
+		{Key: "microseconds", Description: "Duration in microseconds"},
+		{Key: "milliseconds", Description: "Duration in milliseconds"},
+		{Key: "minutes", Description: "Duration in minutes"},
+		{Key: "hours", Description: "Duration in hours"},
+	}
+}
+
+func (tc *TimeConverter) Live() bool {
+	return true
+}
+
 func (tc *TimeConverter) Help() string {
 	return `Time Converter converts time values between different units:
 
@@ -139,23 +205,57 @@ func (tc *TimeConverter) Execute(input string) (string, error) {
 		return "Please provide a time value to convert (e.g., '100ms', '1s', '5min')", nil
 	}
 
-	value, unit, err := parseInput(input)
+	result, err := tc.ExecuteResult(input)
 	if err != nil {
 		return fmt.Sprintf("Error: %v", err), nil
 	}
 
-	result := convertToAllUnits(value, unit)
+	return result.String(), nil
+}
+
+// Validate checks that the input is a number followed by a known unit
+func (tc *TimeConverter) Validate(input string) error {
+	_, _, err := parseInput(input)
+	return err
+}
+
+// Complete suggests units once a number has been typed
+func (tc *TimeConverter) Complete(input string, cursor int) feature.Completions {
+	start := cursor
+	for start > 0 && isUnitLetter(input[start-1]) {
+		start--
+	}
+	end := cursor
+	for end < len(input) && isUnitLetter(input[end]) {
+		end++
+	}
+
+	completions := feature.Completions{Start: start, End: end}
+	if strings.TrimSpace(input[:start]) != "" {
+		completions.Candidates = feature.MatchPrefix(input[start:cursor], unitCompletions)
+	}
+	return completions
+}
+
+func (tc *TimeConverter) ExecuteResult(input string) (*feature.Result, error) {
+	value, unit, err := parseInput(input)
+	if err != nil {
+		return nil, err
+	}
+	input = strings.TrimSpace(input)
+
+	conversion := convertToAllUnits(value, unit)
 
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Time Conversions (%s):\n", input))
-	output.WriteString(fmt.Sprintf("  Nanoseconds:  %s\n", formatNumber(result.Nanoseconds)))
-	output.WriteString(fmt.Sprintf("  Microseconds: %s\n", formatNumber(result.Microseconds)))
-	output.WriteString(fmt.Sprintf("  Milliseconds: %s\n", formatNumber(result.Milliseconds)))
-	output.WriteString(fmt.Sprintf("  Seconds:      %s\n", formatNumber(result.Seconds)))
-	output.WriteString(fmt.Sprintf("  Minutes:      %s\n", formatNumber(result.Minutes)))
-	output.WriteString(fmt.Sprintf("  Hours:        %s\n", formatNumber(result.Hours)))
+	result := feature.NewResult("")
+	result.AddSection(fmt.Sprintf("Time Conversions (%s)", input)).
+		AddFormatted("nanoseconds", "Nanoseconds", conversion.Nanoseconds, formatNumber(conversion.Nanoseconds)).
+		AddFormatted("microseconds", "Microseconds", conversion.Microseconds, formatNumber(conversion.Microseconds)).
+		AddFormatted("milliseconds", "Milliseconds", conversion.Milliseconds, formatNumber(conversion.Milliseconds)).
+		AddFormatted("seconds", "Seconds", conversion.Seconds, formatNumber(conversion.Seconds)).
+		AddFormatted("minutes", "Minutes", conversion.Minutes, formatNumber(conversion.Minutes)).
+		AddFormatted("hours", "Hours", conversion.Hours, formatNumber(conversion.Hours))
 
-	return output.String(), nil
+	return result, nil
 }
 
 func formatNumber(n float64) string {
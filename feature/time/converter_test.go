@@ -1,6 +1,7 @@
 package time
 
 import (
+	"bhelper/feature"
 	"strings"
 	"testing"
 )
@@ -77,6 +78,30 @@ func TestTimeConverter_Execute(t *testing.T) {
 	}
 }
 
+func TestTimeConverter_ExecuteResult(t *testing.T) {
+	converter := NewTimeConverter()
+
+	result, err := converter.ExecuteResult("1.5h")
+	if err != nil {
+		t.Fatalf("ExecuteResult() error = %v", err)
+	}
+
+	fields := make(map[string]any)
+	for _, f := range result.Fields() {
+		fields[f.Key] = f.Value
+	}
+	if fields["seconds"] != 5400.0 {
+		t.Errorf("ExecuteResult() seconds = %v, want 5400", fields["seconds"])
+	}
+	if fields["minutes"] != 90.0 {
+		t.Errorf("ExecuteResult() minutes = %v, want 90", fields["minutes"])
+	}
+
+	if _, err := converter.ExecuteResult("invalid"); err == nil {
+		t.Error("ExecuteResult() expected error for invalid input")
+	}
+}
+
 func TestParseInput(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -266,3 +291,91 @@ func TestConvertToAllUnits(t *testing.T) {
 		})
 	}
 }
+
+func TestTimeConverter_Outputs(t *testing.T) {
+	converter := NewTimeConverter()
+
+	result, err := converter.ExecuteResult("1.5h")
+	if err != nil {
+		t.Fatalf("ExecuteResult() error = %v", err)
+	}
+
+	for _, out := range converter.Outputs() {
+		if _, ok := result.Field(out.Key); !ok {
+			t.Errorf("Outputs() declares %q, missing from result", out.Key)
+		}
+	}
+
+	value, err := feature.SelectOutput(converter, result, "")
+	if err != nil || value != "5400" {
+		t.Errorf("SelectOutput() default = %q, %v, want 5400", value, err)
+	}
+	if _, err := feature.SelectOutput(converter, result, "days"); err == nil {
+		t.Error("SelectOutput() expected error for unknown output")
+	}
+}
+
+func TestParseInputErrorPositions(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		start, end int
+		suggestion string
+	}{
+		{"no unit", " 100 ", 4, 4, "add a unit: ns, us, ms, s, min or h"},
+		{"invalid number", "1.2.3s", 0, 5, "start with a number, e.g. 1.5h"},
+		{"alias", "5 Seconds", 2, 9, `did you mean "s"?`},
+		{"unknown unit", "100xyz", 3, 6, "use one of ns, us, ms, s, min or h"},
+	}
+
+	tc := NewTimeConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ie, ok := feature.AsInputError(tc.Validate(tt.input))
+			if !ok {
+				t.Fatalf("Validate(%q) expected an input error", tt.input)
+			}
+			if ie.Start != tt.start || ie.End != tt.end || ie.Suggestion != tt.suggestion {
+				t.Errorf("Validate() = [%d,%d) %q, want [%d,%d) %q", ie.Start, ie.End, ie.Suggestion, tt.start, tt.end, tt.suggestion)
+			}
+		})
+	}
+
+	if err := tc.Validate("1.5h"); err != nil {
+		t.Errorf("Validate() error = %v for valid input", err)
+	}
+}
+
+func TestTimeConverter_Complete(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		cursor int
+		want   []string
+	}{
+		{"after number", "100", 3, []string{"ns", "us", "ms", "s", "min", "h"}},
+		{"unit prefix", "1.5 m", 5, []string{"ms", "min"}},
+		{"case insensitive", "5M", 2, []string{"ms", "min"}},
+		{"no number", "m", 1, nil},
+		{"empty", "", 0, nil},
+	}
+
+	tc := NewTimeConverter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tc.Complete(tt.input, tt.cursor)
+			var got []string
+			for _, candidate := range c.Candidates {
+				got = append(got, candidate.Value)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Complete(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	c := tc.Complete("5mi", 2)
+	if got, _ := c.Apply("5mi", 0); got != "5ms" {
+		t.Errorf("Apply() = %q, want 5ms", got)
+	}
+}
@@ -2,15 +2,48 @@ package feature
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // TimezoneAnalyzer provides comprehensive timezone and time information
-type TimezoneAnalyzer struct{}
+type TimezoneAnalyzer struct {
+	location *time.Location
+	// zones lists the zone names offered for completion and suggestions
+	zones func() []string
+}
 
 func NewTimezoneAnalyzer() *TimezoneAnalyzer {
-	return &TimezoneAnalyzer{}
+	return &TimezoneAnalyzer{zones: zoneNames}
+}
+
+func (ta *TimezoneAnalyzer) OptionSpecs() []OptionSpec {
+	return []OptionSpec{
+		{
+			Key:         "location",
+			Type:        OptionString,
+			Default:     "",
+			Description: "IANA time zone dates are interpreted in (e.g. Europe/Berlin), UTC when empty",
+		},
+	}
+}
+
+func (ta *TimezoneAnalyzer) Configure(opts Options) error {
+	name := opts.String("location")
+	if name == "" {
+		ta.location = nil
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid location %q: %v", name, err)
+	}
+	ta.location = loc
+	return nil
 }
 
 func (ta *TimezoneAnalyzer) ID() string {
@@ -25,11 +58,46 @@ func (ta *TimezoneAnalyzer) Description() string {
 	return "Get Unix timestamp for a date (dd-mm-yyyy)"
 }
 
+func (ta *TimezoneAnalyzer) Category() string {
+	return "Time"
+}
+
+func (ta *TimezoneAnalyzer) Tags() []string {
+	return []string{"date", "unix", "timestamp", "epoch"}
+}
+
+func (ta *TimezoneAnalyzer) Outputs() []Output {
+	return []Output{
+		{Key: "unix", Description: "Unix timestamp in seconds"},
+		{Key: "date", Description: "Date as yyyy-mm-dd"},
+		{Key: "time", Description: "Local time as hh:mm:ss"},
+		{Key: "utc_time", Description: "UTC time as hh:mm:ss"},
+		{Key: "day_of_week", Description: "Name of the weekday"},
+		{Key: "iso_week", Description: "ISO 8601 week number"},
+		{Key: "julian_day", Description: "Julian day number"},
+	}
+}
+
+// VolatileKeys returns every field that follows the clock when the input is
+// empty and the current time is shown
+func (ta *TimezoneAnalyzer) VolatileKeys(input string) []string {
+	if input != "" {
+		return nil
+	}
+	return []string{"date", "time", "offset", "utc_time", "unix", "day_of_week",
+		"day_of_year", "iso_week", "julian_day", "season", "leap_year"}
+}
+
+func (ta *TimezoneAnalyzer) Live() bool {
+	return true
+}
+
 func (ta *TimezoneAnalyzer) Help() string {
 	return `Timezone Analyzer displays Unix timestamp and timezone information for a specific date.
 
 Input Format:
   • dd-mm-yyyy (e.g., 16-01-2026 for January 16, 2026)
+  • dd-mm-yyyy zone to use an IANA time zone (e.g., 16-01-2026 Asia/Tokyo)
 
 Outputs:
   • Unix timestamp (seconds, milliseconds, microseconds, nanoseconds)
@@ -47,58 +115,223 @@ func (ta *TimezoneAnalyzer) Examples() []Example {
 		{Input: "16-01-2026", Description: "Get Unix timestamp for January 16, 2026"},
 		{Input: "01-01-2024", Description: "Get Unix timestamp for January 1, 2024"},
 		{Input: "25-12-2025", Description: "Get Unix timestamp for December 25, 2025"},
+		{Input: "16-01-2026 Asia/Tokyo", Description: "Get Unix timestamp for January 16, 2026 in Tokyo"},
 	}
 }
 
 func (ta *TimezoneAnalyzer) Execute(input string) (string, error) {
-	targetTime, err := ta.parseDate(input)
+	result, err := ta.ExecuteResult(input)
 	if err != nil {
 		return "", err
 	}
+	return result.String(), nil
+}
 
-	localZone, _ := targetTime.Zone()
-
-	var result strings.Builder
-
-	result.WriteString(ta.renderTimeFormats(targetTime, localZone) + "\n")
+func (ta *TimezoneAnalyzer) ExecuteResult(input string) (*Result, error) {
+	targetTime, err := ta.parseDate(input)
+	if err != nil {
+		return nil, err
+	}
 
-	return result.String(), nil
+	return ta.buildResult(targetTime), nil
 }
 
 func (ta *TimezoneAnalyzer) parseDate(input string) (time.Time, error) {
 	if input == "" {
+		if ta.location != nil {
+			return time.Now().In(ta.location), nil
+		}
 		return time.Now(), nil
 	}
 
-	parsed, err := time.Parse("02-01-2006", input)
+	loc := ta.location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if err := checkDate(input); err != nil {
+		return time.Time{}, err
+	}
+
+	// An IANA zone may follow the date, overriding the configured location
+	date, zone, _ := strings.Cut(input, " ")
+	if name := strings.TrimSpace(zone); name != "" {
+		zl, err := time.LoadLocation(name)
+		if err != nil {
+			return time.Time{}, ta.zoneError(input, zone)
+		}
+		loc = zl
+	}
+
+	parsed, err := time.ParseInLocation("02-01-2006", date, loc)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid date format. Use dd-mm-yyyy (e.g., 16-01-2026)")
+		return time.Time{}, NewInputError(input, 0, len(date), dateFormatError, "")
 	}
 
 	return parsed, nil
 }
 
-func (ta *TimezoneAnalyzer) renderTimeFormats(now time.Time, zoneName string) string {
-	var b strings.Builder
+// Validate checks that the input is empty or a dd-mm-yyyy date, optionally
+// followed by a known zone. Zones are looked up in the cached zone list,
+// the zone database itself is only read when the input runs.
+func (ta *TimezoneAnalyzer) Validate(input string) error {
+	if input == "" {
+		return nil
+	}
+	if err := checkDate(input); err != nil {
+		return err
+	}
 
-	b.WriteString(fmt.Sprintf("Date:          %s\n", now.Format("2006-01-02")))
-	b.WriteString(fmt.Sprintf("Time:          %s %s\n", now.Format("15:04:05"), ta.formatOffset(now)))
-	b.WriteString(fmt.Sprintf("UTC Time:      %s UTC\n", now.UTC().Format("15:04:05")))
-	b.WriteString(fmt.Sprintf("Unix Timestamp: %d\n", now.Unix()))
+	_, zone, _ := strings.Cut(input, " ")
+	if name := strings.TrimSpace(zone); name != "" && !slices.Contains(ta.zones(), name) {
+		return ta.zoneError(input, zone)
+	}
+	return nil
+}
 
-	dayOfWeek := now.Weekday()
-	dayOfYear := ta.dayOfYear(now)
-	weekNumber := ta.isoWeekNumber(now)
-	isLeap := ta.isLeapYear(now.Year())
+// zoneError underlines an unknown zone, the input after the date
+func (ta *TimezoneAnalyzer) zoneError(input, zone string) error {
+	name := strings.TrimSpace(zone)
+	start := len(input) - len(strings.TrimLeft(zone, " "))
+	return NewInputError(input, start, start+len(name),
+		fmt.Sprintf("unknown time zone: %s", name), ta.suggestZone(name))
+}
+
+// Complete suggests IANA zone names after the date, matching either the
+// whole name or its last part, so "berl" finds Europe/Berlin
+func (ta *TimezoneAnalyzer) Complete(input string, cursor int) Completions {
+	start := strings.LastIndexByte(input[:cursor], ' ') + 1
+	end := len(input)
+	if i := strings.IndexByte(input[cursor:], ' '); i >= 0 {
+		end = cursor + i
+	}
+	completions := Completions{Start: start, End: end}
+
+	// Only the word right after the date is a zone
+	date := strings.TrimSpace(input[:start])
+	if date == "" || strings.Contains(date, " ") {
+		return completions
+	}
+
+	prefix := strings.ToLower(input[start:cursor])
+	var byCity []Completion
+	for _, name := range ta.zones() {
+		lower := strings.ToLower(name)
+		switch {
+		case strings.HasPrefix(lower, prefix):
+			completions.Candidates = append(completions.Candidates, Completion{Value: name})
+		case strings.HasPrefix(lower[strings.LastIndexByte(lower, '/')+1:], prefix):
+			byCity = append(byCity, Completion{Value: name})
+		}
+	}
+	completions.Candidates = append(completions.Candidates, byCity...)
+	return completions
+}
+
+// Warm reads the zone database, which takes a moment the first time
+func (ta *TimezoneAnalyzer) Warm() {
+	ta.zones()
+}
+
+// suggestZone proposes a zone for a misspelled one, comparing names
+// without regard to case
+func (ta *TimezoneAnalyzer) suggestZone(zone string) string {
+	for _, name := range ta.zones() {
+		if strings.EqualFold(name, zone) {
+			return fmt.Sprintf("did you mean %q?", name)
+		}
+	}
+	return "use an IANA zone such as Europe/Berlin"
+}
+
+const (
+	// dateLayout is the accepted date format, letters standing for digits
+	dateLayout      = "dd-mm-yyyy"
+	dateFormatError = "invalid date format. Use dd-mm-yyyy (e.g., 16-01-2026)"
+)
+
+// checkDate points at the first character of the date, the input up to
+// the first space, that does not fit dd-mm-yyyy, then checks the month and
+// the day
+func checkDate(input string) error {
+	date, _, _ := strings.Cut(input, " ")
+	for i := 0; i < len(date) && i < len(dateLayout); i++ {
+		c := input[i]
+		if dateLayout[i] == '-' {
+			if c == '-' {
+				continue
+			}
+			hint := "separate day, month and year with '-'"
+			if c >= '0' && c <= '9' {
+				hint = "write the day and the month with two digits, e.g. 05"
+			}
+			return charError(input, i, hint)
+		}
+
+		if c < '0' || c > '9' {
+			hint := "expected a digit"
+			if c == '-' || c == '/' || c == '.' {
+				hint = "write the day and the month with two digits, e.g. 05"
+			}
+			return charError(input, i, hint)
+		}
+	}
+
+	if len(date) < len(dateLayout) {
+		return NewInputError(input, len(date), len(date), dateFormatError,
+			fmt.Sprintf("continue with %s", dateLayout[len(date):]))
+	}
+	if len(date) > len(dateLayout) {
+		return NewInputError(input, len(dateLayout), len(date), dateFormatError, "remove the extra characters")
+	}
 
-	b.WriteString(fmt.Sprintf("Day of Week:      %s\n", dayOfWeek.String()))
-	b.WriteString(fmt.Sprintf("Day of Year:      %d/%d\n", dayOfYear, ta.daysInYear(now.Year())))
-	b.WriteString(fmt.Sprintf("ISO Week Number:  %d\n", weekNumber))
-	b.WriteString(fmt.Sprintf("Julian Day:       %d\n", ta.julianDay(now)))
-	b.WriteString(fmt.Sprintf("Season:           %s\n", ta.season(now)))
-	b.WriteString(fmt.Sprintf("Leap Year:        %t\n", isLeap))
+	day, _ := strconv.Atoi(input[0:2])
+	month, _ := strconv.Atoi(input[3:5])
+	year, _ := strconv.Atoi(input[6:10])
+	if month < 1 || month > 12 {
+		return NewInputError(input, 3, 5, fmt.Sprintf("invalid month %s", input[3:5]), "use a month between 01 and 12")
+	}
 
-	return b.String()
+	days := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day < 1 || day > days {
+		return NewInputError(input, 0, 2,
+			fmt.Sprintf("invalid day %s for %s %d", input[0:2], time.Month(month), year),
+			fmt.Sprintf("use a day between 01 and %d", days))
+	}
+	return nil
+}
+
+// charError underlines the whole character at byte offset i, not only its
+// first byte
+func charError(input string, i int, hint string) error {
+	_, size := utf8.DecodeRuneInString(input[i:])
+	return NewInputError(input, i, i+size, dateFormatError, hint)
+}
+
+func (ta *TimezoneAnalyzer) buildResult(now time.Time) *Result {
+	result := NewResult("")
+
+	zoneName, _ := now.Zone()
+	offset := ta.formatOffset(now)
+	result.AddSection("Time").
+		Add("date", "Date", now.Format("2006-01-02")).
+		AddFormatted("time", "Time", now.Format("15:04:05"), now.Format("15:04:05")+" "+offset).
+		Add("zone", "Zone", zoneName).
+		Add("offset", "Offset", offset).
+		AddFormatted("utc_time", "UTC Time", now.UTC().Format("15:04:05"), now.UTC().Format("15:04:05")+" UTC").
+		Add("unix", "Unix Timestamp", now.Unix())
+
+	dayOfYear := ta.dayOfYear(now)
+	daysInYear := ta.daysInYear(now.Year())
+	result.AddSection("Calendar").
+		Add("day_of_week", "Day of Week", now.Weekday().String()).
+		AddFormatted("day_of_year", "Day of Year", dayOfYear, fmt.Sprintf("%d/%d", dayOfYear, daysInYear)).
+		Add("iso_week", "ISO Week Number", ta.isoWeekNumber(now)).
+		Add("julian_day", "Julian Day", ta.julianDay(now)).
+		Add("season", "Season", ta.season(now)).
+		Add("leap_year", "Leap Year", ta.isLeapYear(now.Year()))
+
+	return result
 }
 
 func (ta *TimezoneAnalyzer) formatOffset(t time.Time) string {
@@ -0,0 +1,215 @@
+package feature
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTimezoneValidate(t *testing.T) {
+	tests := []struct {
+		input      string
+		start      int
+		end        int
+		message    string
+		suggestion string
+	}{
+		{input: ""},
+		{input: "16-01-2026"},
+		{input: "29-02-2024"},
+		{input: "16-01-2026 Asia/Tokyo"},
+		{input: "16-01-2026  Europe/Berlin "},
+		{input: "16-01", start: 5, end: 5, message: dateFormatError, suggestion: "continue with -yyyy"},
+		{input: "16-01-20261", start: 10, end: 11, message: dateFormatError, suggestion: "remove the extra characters"},
+		{input: "6-01-2026", start: 1, end: 2, message: dateFormatError, suggestion: "write the day and the month with two digits, e.g. 05"},
+		{input: "16/01/2026", start: 2, end: 3, message: dateFormatError, suggestion: "separate day, month and year with '-'"},
+		{input: "16-0a-2026", start: 4, end: 5, message: dateFormatError, suggestion: "expected a digit"},
+		// A multibyte character is underlined as a whole
+		{input: "16–01-2026", start: 2, end: 5, message: dateFormatError, suggestion: "separate day, month and year with '-'"},
+		{input: "1é-01-2026", start: 1, end: 3, message: dateFormatError, suggestion: "expected a digit"},
+		{input: "16-13-2026", start: 3, end: 5, message: "invalid month 13", suggestion: "use a month between 01 and 12"},
+		{input: "16-00-2026", start: 3, end: 5, message: "invalid month 00", suggestion: "use a month between 01 and 12"},
+		{input: "00-01-2026", start: 0, end: 2, message: "invalid day 00 for January 2026", suggestion: "use a day between 01 and 31"},
+		{input: "29-02-2026", start: 0, end: 2, message: "invalid day 29 for February 2026", suggestion: "use a day between 01 and 28"},
+		{input: "31-04-2026 Asia/Tokyo", start: 0, end: 2, message: "invalid day 31 for April 2026", suggestion: "use a day between 01 and 30"},
+		{input: "16-01-2026 Mars/Olympus", start: 11, end: 23, message: "unknown time zone: Mars/Olympus", suggestion: "use an IANA zone such as Europe/Berlin"},
+		{input: "16-01-2026  asia/tokyo", start: 12, end: 22, message: "unknown time zone: asia/tokyo", suggestion: `did you mean "Asia/Tokyo"?`},
+	}
+
+	ta := NewTimezoneAnalyzer()
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			err := ta.Validate(tt.input)
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			ie, ok := AsInputError(err)
+			if !ok {
+				t.Fatalf("Expected an input error, got %v", err)
+			}
+			if ie.Start != tt.start || ie.End != tt.end {
+				t.Errorf("Expected span [%d, %d), got [%d, %d)", tt.start, tt.end, ie.Start, ie.End)
+			}
+			if ie.Message != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, ie.Message)
+			}
+			if ie.Suggestion != tt.suggestion {
+				t.Errorf("Expected suggestion %q, got %q", tt.suggestion, ie.Suggestion)
+			}
+		})
+	}
+}
+
+func TestTimezoneValidateUsesZoneList(t *testing.T) {
+	ta := &TimezoneAnalyzer{zones: func() []string { return []string{"Asia/Tokyo"} }}
+
+	if err := ta.Validate("16-01-2026 Asia/Tokyo"); err != nil {
+		t.Errorf("Expected a listed zone to be valid, got %v", err)
+	}
+	if err := ta.Validate("16-01-2026 Europe/Berlin"); err == nil {
+		t.Error("Expected a zone missing from the list to be rejected")
+	}
+}
+
+func TestTimezoneZoneOverridesLocation(t *testing.T) {
+	ta := NewTimezoneAnalyzer()
+	result, err := ta.ExecuteResult("16-01-2026 Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	values := NewDocument(ta.ID(), "", result).Values
+	if got := FormatValue(values["offset"]); got != "+09:00" {
+		t.Errorf("Expected the Tokyo offset, got %s", got)
+	}
+	if got := FormatValue(values["unix"]); got != "1768489200" {
+		t.Errorf("Expected midnight in Tokyo, got %s", got)
+	}
+}
+
+func TestTimezoneComplete(t *testing.T) {
+	ta := &TimezoneAnalyzer{zones: func() []string {
+		return []string{"America/New_York", "Asia/Tokyo", "Europe/Berlin", "Europe/Paris", "UTC"}
+	}}
+
+	tests := []struct {
+		name   string
+		input  string
+		cursor int
+		start  int
+		end    int
+		want   []string
+	}{
+		{"no date", "eur", 3, 0, 3, nil},
+		{"right after the date", "16-01-2026 ", 11, 11, 11, []string{"America/New_York", "Asia/Tokyo", "Europe/Berlin", "Europe/Paris", "UTC"}},
+		{"by prefix", "16-01-2026 eu", 13, 11, 13, []string{"Europe/Berlin", "Europe/Paris"}},
+		// Whole-name matches come before city matches
+		{"by city", "16-01-2026 u", 12, 11, 12, []string{"UTC"}},
+		{"city only", "16-01-2026 tok", 14, 11, 14, []string{"Asia/Tokyo"}},
+		{"cursor inside the zone", "16-01-2026 Europe/Ber", 13, 11, 21, []string{"Europe/Berlin", "Europe/Paris"}},
+		{"second word after the zone", "16-01-2026 UTC eu", 17, 15, 17, nil},
+		{"no match", "16-01-2026 mars", 15, 11, 15, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Complete(ta, tt.input, tt.cursor)
+			if c.Start != tt.start || c.End != tt.end {
+				t.Errorf("Expected range [%d, %d), got [%d, %d)", tt.start, tt.end, c.Start, c.End)
+			}
+			var got []string
+			for _, candidate := range c.Candidates {
+				got = append(got, candidate.Value)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTimezoneSuggestZone(t *testing.T) {
+	ta := &TimezoneAnalyzer{zones: func() []string { return []string{"Europe/Berlin"} }}
+
+	if got := ta.suggestZone("europe/berlin"); got != `did you mean "Europe/Berlin"?` {
+		t.Errorf("Expected a suggestion, got %q", got)
+	}
+	if got := ta.suggestZone("Europe/Bern"); got != "use an IANA zone such as Europe/Berlin" {
+		t.Errorf("Expected the generic hint, got %q", got)
+	}
+}
+
+// writeZoneFiles creates zone data files with the given names under dir
+func writeZoneFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("TZif2 zone data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestListZoneDir(t *testing.T) {
+	dir := t.TempDir()
+	writeZoneFiles(t, dir,
+		"UTC",
+		"Europe/Berlin",
+		"America/Argentina/Buenos_Aires",
+		"posix/Europe/Berlin",
+		"right/UTC",
+		"Factory",
+		"localtime",
+		"posixrules",
+		"zone1970.tab",
+		"leap-seconds",
+		"Etc/UTC",
+	)
+	// A file without the TZif magic is not a zone
+	if err := os.WriteFile(filepath.Join(dir, "Etc", "Notzone"), []byte("text"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"America/Argentina/Buenos_Aires", "Etc/UTC", "Europe/Berlin", "UTC"}
+	if got := listZoneDir(dir); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := listZoneDir(filepath.Join(dir, "missing")); len(got) != 0 {
+		t.Errorf("Expected no zones in a missing directory, got %v", got)
+	}
+}
+
+func TestListZoneZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zoneinfo.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for _, name := range []string{"Europe/", "Europe/Berlin", "UTC", "zone.tab", "Factory"} {
+		if _, err := w.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	want := []string{"Europe/Berlin", "UTC"}
+	if got := listZoneZip(path); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got := listZoneZip(filepath.Join(t.TempDir(), "missing.zip")); got != nil {
+		t.Errorf("Expected no zones without an archive, got %v", got)
+	}
+}
@@ -0,0 +1,87 @@
+package feature
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// InputError is a problem located in the input. Start and End are byte
+// offsets of the offending part, End == Start points between two
+// characters, such as where something is missing. Suggestion optionally
+// tells how to fix it.
+type InputError struct {
+	Start      int
+	End        int
+	Message    string
+	Suggestion string
+	// input is the text the offsets refer to, used to report columns
+	input string
+}
+
+// NewInputError creates an error covering input[start:end]
+func NewInputError(input string, start, end int, message, suggestion string) *InputError {
+	start = min(max(start, 0), len(input))
+	end = min(max(end, start), len(input))
+	return &InputError{Start: start, End: end, Message: message, Suggestion: suggestion, input: input}
+}
+
+// Error returns the message with the column it refers to
+func (e *InputError) Error() string {
+	line, col := e.Position()
+	if strings.Contains(e.input, "\n") {
+		return fmt.Sprintf("%s (line %d, column %d)", e.Message, line, col)
+	}
+	return fmt.Sprintf("%s (column %d)", e.Message, col)
+}
+
+// Position returns the 1-based line and column, counted in characters, at
+// which the error starts
+func (e *InputError) Position() (line, col int) {
+	before := e.input[:min(e.Start, len(e.input))]
+	line = strings.Count(before, "\n") + 1
+	if i := strings.LastIndex(before, "\n"); i >= 0 {
+		before = before[i+1:]
+	}
+	return line, utf8.RuneCountInString(before) + 1
+}
+
+// Context returns the input line the error is on and a marker line that
+// underlines the offending part with carets
+func (e *InputError) Context() (line, marker string) {
+	start := min(e.Start, len(e.input))
+	lineStart := strings.LastIndex(e.input[:start], "\n") + 1
+	lineEnd := len(e.input)
+	if i := strings.Index(e.input[lineStart:], "\n"); i >= 0 {
+		lineEnd = lineStart + i
+	}
+
+	col := utf8.RuneCountInString(e.input[lineStart:start])
+	width := utf8.RuneCountInString(e.input[start:max(min(e.End, lineEnd), start)])
+	return e.input[lineStart:lineEnd], strings.Repeat(" ", col) + strings.Repeat("^", max(width, 1))
+}
+
+// ValidatingFeature is implemented by features that can check an input
+// without running. Validate must be cheap, it runs on every keystroke.
+type ValidatingFeature interface {
+	// Validate returns nil for a valid input, preferably an *InputError
+	// otherwise
+	Validate(input string) error
+}
+
+// Validate checks an input with the feature's validator. Features without
+// one accept every input.
+func Validate(f Feature, input string) error {
+	if v, ok := f.(ValidatingFeature); ok {
+		return v.Validate(input)
+	}
+	return nil
+}
+
+// AsInputError returns the located input error wrapped in err, if any
+func AsInputError(err error) (*InputError, bool) {
+	var ie *InputError
+	ok := errors.As(err, &ie)
+	return ie, ok
+}
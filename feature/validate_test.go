@@ -0,0 +1,58 @@
+package feature
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestInputErrorPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		start  int
+		end    int
+		line   int
+		col    int
+		text   string
+		marker string
+		err    string
+	}{
+		{"start", "abc", 0, 1, 1, 1, "abc", "^", "bad (column 1)"},
+		{"middle", "base64:x:10/sec", 7, 8, 1, 8, "base64:x:10/sec", "       ^", "bad (column 8)"},
+		{"missing part", "base64:", 7, 7, 1, 8, "base64:", "       ^", "bad (column 8)"},
+		// Columns count characters, not bytes
+		{"multibyte before", "héllo wörld", 7, 13, 1, 7, "héllo wörld", "      ^^^^^", "bad (column 7)"},
+		{"multibyte span", "1é-01", 1, 3, 1, 2, "1é-01", " ^", "bad (column 2)"},
+		{"second line", "first\nsecond\nthird", 9, 12, 2, 4, "second", "   ^^^", "bad (line 2, column 4)"},
+		{"line start", "first\nsecond", 6, 6, 2, 1, "second", "^", "bad (line 2, column 1)"},
+		// A span past the end of the line is cut at the line
+		{"across lines", "first\nsecond", 3, 9, 1, 4, "first", "   ^^", "bad (line 1, column 4)"},
+		{"clamped", "abc", 5, 9, 1, 4, "abc", "   ^", "bad (column 4)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewInputError(tt.input, tt.start, tt.end, "bad", "")
+			if line, col := e.Position(); line != tt.line || col != tt.col {
+				t.Errorf("Expected line %d, column %d, got line %d, column %d", tt.line, tt.col, line, col)
+			}
+			if text, marker := e.Context(); text != tt.text || marker != tt.marker {
+				t.Errorf("Expected %q / %q, got %q / %q", tt.text, tt.marker, text, marker)
+			}
+			if e.Error() != tt.err {
+				t.Errorf("Expected %q, got %q", tt.err, e.Error())
+			}
+		})
+	}
+}
+
+func TestAsInputError(t *testing.T) {
+	e := NewInputError("abc", 1, 2, "bad", "fix it")
+	if got, ok := AsInputError(fmt.Errorf("stage 1: %w", e)); !ok || got != e {
+		t.Errorf("Expected the wrapped input error, got %v", got)
+	}
+	if _, ok := AsInputError(errors.New("plain")); ok {
+		t.Error("Expected no input error in a plain error")
+	}
+}
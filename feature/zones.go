@@ -0,0 +1,118 @@
+package feature
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// zoneDirs are the places the time package looks for the zone database
+var zoneDirs = []string{
+	"/usr/share/zoneinfo/",
+	"/usr/share/lib/zoneinfo/",
+	"/usr/lib/locale/TZ/",
+	"/etc/zoneinfo/",
+}
+
+// fallbackZones are offered when no zone database can be listed
+var fallbackZones = []string{
+	"UTC",
+	"Africa/Cairo", "Africa/Johannesburg", "Africa/Lagos",
+	"America/Chicago", "America/Denver", "America/Los_Angeles", "America/New_York", "America/Sao_Paulo", "America/Toronto",
+	"Asia/Dubai", "Asia/Hong_Kong", "Asia/Jakarta", "Asia/Kolkata", "Asia/Seoul", "Asia/Shanghai", "Asia/Singapore", "Asia/Tokyo",
+	"Australia/Melbourne", "Australia/Sydney",
+	"Europe/Amsterdam", "Europe/Berlin", "Europe/Istanbul", "Europe/London", "Europe/Madrid", "Europe/Moscow", "Europe/Paris",
+	"Pacific/Auckland",
+}
+
+// zoneNames lists the IANA zone names of the local zone database, read
+// once on first use
+var zoneNames = sync.OnceValue(func() []string {
+	if path := os.Getenv("ZONEINFO"); path != "" {
+		if names := listZoneZip(path); len(names) > 0 {
+			return names
+		}
+		if names := listZoneDir(path); len(names) > 0 {
+			return names
+		}
+	}
+	for _, dir := range zoneDirs {
+		if names := listZoneDir(dir); len(names) > 0 {
+			return names
+		}
+	}
+	return fallbackZones
+})
+
+// listZoneDir lists the zone files below dir, skipping the alternative
+// posix and right trees and files that are not zone data
+func listZoneDir(dir string) []string {
+	var names []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name, _ := filepath.Rel(dir, path)
+		name = filepath.ToSlash(name)
+		if d.IsDir() {
+			if name == "posix" || name == "right" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isZoneName(name) && isZoneFile(path) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	slices.Sort(names)
+	return names
+}
+
+// listZoneZip lists the zones of a zoneinfo.zip such as the one shipped
+// with Go
+func listZoneZip(path string) []string {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+
+	var names []string
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, "/") && isZoneName(f.Name) {
+			names = append(names, f.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// isZoneName filters out the helper files kept next to the zones
+func isZoneName(name string) bool {
+	if name == "" || strings.Contains(name, ".") {
+		return false
+	}
+	switch name {
+	case "Factory", "localtime", "posixrules":
+		return false
+	}
+	return name[0] >= 'A' && name[0] <= 'Z'
+}
+
+// isZoneFile checks for the TZif magic at the start of the file
+func isZoneFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	n, _ := f.Read(magic)
+	return n == 4 && string(magic) == "TZif"
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bhelper/feature"
+	"bhelper/fuzzy"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// listEntry is a feature shown in the feature list, with the rune positions
+// matched by the filter used for highlighting
+type listEntry struct {
+	feature     feature.Feature
+	category    string
+	favorite    bool
+	recent      bool
+	nameMatches []int
+	descMatches []int
+	score       int
+}
+
+// favoritesCategory is the heading of pinned features
+const favoritesCategory = "★ Favorites"
+
+// recentCategory is the heading of recently used features
+const recentCategory = "⏱ Recent"
+
+// visibleFeatures returns the features shown in the list: pinned features
+// first and the rest grouped by category when no filter is set, ranked by
+// fuzzy score otherwise
+func (c CLI) visibleFeatures() []listEntry {
+	features := c.features
+	pattern := strings.TrimSpace(c.filterInput.Value())
+
+	if pattern == "" {
+		return groupByCategory(features, c.recentFeatures(), c.isFavorite)
+	}
+
+	var entries []listEntry
+	for _, f := range features {
+		entry, ok := matchFeature(pattern, f)
+		if ok {
+			entry.favorite = c.isFavorite(f.ID())
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].score > entries[j].score
+	})
+	return entries
+}
+
+// isFavorite reports whether a feature is pinned to the top of the list
+func (c CLI) isFavorite(id string) bool {
+	return c.presets != nil && c.presets.IsFavorite(id)
+}
+
+// indexOf returns the position of a feature in the visible list
+func (c CLI) indexOf(id string) int {
+	for i, e := range c.visibleFeatures() {
+		if e.feature.ID() == id {
+			return i
+		}
+	}
+	return 0
+}
+
+// groupByCategory orders features by category, keeping categories in the
+// order they first appear and features in list order within them.
+// Recently used features are repeated in a group at the top, favorites are
+// moved to a group of their own below it.
+func groupByCategory(features, recent []feature.Feature, isFavorite func(id string) bool) []listEntry {
+	rank := map[string]int{recentCategory: -2, favoritesCategory: -1}
+	entries := make([]listEntry, 0, len(recent)+len(features))
+	for _, f := range recent {
+		entries = append(entries, listEntry{feature: f, category: recentCategory, recent: true})
+	}
+	for _, f := range features {
+		category := feature.CategoryOf(f)
+		favorite := isFavorite(f.ID())
+		if favorite {
+			category = favoritesCategory
+		}
+		if _, ok := rank[category]; !ok {
+			rank[category] = len(rank)
+		}
+		entries = append(entries, listEntry{feature: f, category: category, favorite: favorite})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return rank[entries[i].category] < rank[entries[j].category]
+	})
+	return entries
+}
+
+// matchFeature fuzzy-matches a pattern against a feature's ID, name,
+// description and tags. Name and ID matches weigh more than the rest.
+func matchFeature(pattern string, f feature.Feature) (listEntry, bool) {
+	entry := listEntry{feature: f, category: feature.CategoryOf(f)}
+	matched := false
+
+	consider := func(score int, weight int) {
+		if !matched || score/weight > entry.score {
+			entry.score = score / weight
+		}
+		matched = true
+	}
+
+	if score, positions, ok := fuzzy.Match(pattern, f.Name()); ok {
+		entry.nameMatches = positions
+		consider(score, 1)
+	}
+	if score, _, ok := fuzzy.Match(pattern, f.ID()); ok {
+		consider(score, 1)
+	}
+	if score, positions, ok := fuzzy.Match(pattern, f.Description()); ok {
+		entry.descMatches = positions
+		consider(score, 2)
+	}
+	for _, tag := range feature.TagsOf(f) {
+		if score, _, ok := fuzzy.Match(pattern, tag); ok {
+			consider(score, 2)
+		}
+	}
+
+	return entry, matched
+}
+
+// highlight renders text with the runes at the given positions emphasized
+func (st styles) highlight(text string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(text)
+	}
+
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p] = true
+	}
+
+	var b strings.Builder
+	for i, r := range []rune(text) {
+		if matched[i] {
+			b.WriteString(st.match.Inherit(base).Render(string(r)))
+		} else {
+			b.WriteString(base.Render(string(r)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package fuzzy
+
+import (
+	"unicode"
+)
+
+const (
+	scoreMatch       = 1
+	bonusConsecutive = 5
+	bonusWordStart   = 8
+	bonusFirstRune   = 10
+	maxGapPenalty    = 5
+)
+
+// Match reports whether every rune of pattern appears in text in order,
+// ignoring case. It returns a score where higher is a better match and the
+// rune positions in text that matched.
+func Match(pattern, text string) (int, []int, bool) {
+	p := []rune(pattern)
+	t := []rune(text)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	bestScore := -1
+	var bestPositions []int
+
+	// Try every occurrence of the first rune as a starting point and keep the best run
+	for start := range t {
+		if !equalFold(t[start], p[0]) {
+			continue
+		}
+
+		positions, ok := matchFrom(p, t, start)
+		if !ok {
+			break
+		}
+
+		score := scorePositions(t, positions)
+		if score > bestScore {
+			bestScore = score
+			bestPositions = positions
+		}
+	}
+
+	if bestScore < 0 {
+		return 0, nil, false
+	}
+	return bestScore, bestPositions, true
+}
+
+// matchFrom greedily matches pattern runes in text starting at start
+func matchFrom(p, t []rune, start int) ([]int, bool) {
+	positions := make([]int, 0, len(p))
+	pi := 0
+	for ti := start; ti < len(t) && pi < len(p); ti++ {
+		if equalFold(t[ti], p[pi]) {
+			positions = append(positions, ti)
+			pi++
+		}
+	}
+	return positions, pi == len(p)
+}
+
+// scorePositions rewards consecutive runs and word starts, and penalizes gaps
+func scorePositions(t []rune, positions []int) int {
+	score := 0
+	for i, pos := range positions {
+		score += scoreMatch
+
+		if pos == 0 {
+			score += bonusFirstRune
+		}
+		if isWordStart(t, pos) {
+			score += bonusWordStart
+		}
+
+		if i > 0 {
+			gap := pos - positions[i-1] - 1
+			if gap == 0 {
+				score += bonusConsecutive
+			} else {
+				score -= min(gap, maxGapPenalty)
+			}
+		}
+	}
+	return score
+}
+
+// isWordStart reports whether the rune at pos begins a word
+func isWordStart(t []rune, pos int) bool {
+	if pos == 0 {
+		return true
+	}
+	prev, cur := t[pos-1], t[pos]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+func equalFold(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
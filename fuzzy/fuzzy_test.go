@@ -0,0 +1,44 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		wantOK    bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"col", "Collision Analyzer", true, []int{0, 1, 2}},
+		{"ca", "Collision Analyzer", true, []int{0, 10}},
+		{"tz", "timezone", true, []int{0, 4}},
+		{"xyz", "timezone", false, nil},
+		{"zt", "timezone", false, nil},
+	}
+
+	for _, tt := range tests {
+		_, positions, ok := Match(tt.pattern, tt.text)
+		if ok != tt.wantOK {
+			t.Errorf("Match(%q, %q) ok = %v, want %v", tt.pattern, tt.text, ok, tt.wantOK)
+			continue
+		}
+		if ok && !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("Match(%q, %q) positions = %v, want %v", tt.pattern, tt.text, positions, tt.positions)
+		}
+	}
+}
+
+func TestMatchPrefersWordStarts(t *testing.T) {
+	// "an" should match the start of "Analyzer" rather than inside "Character"
+	_, positions, ok := Match("an", "Character Analyzer")
+	if !ok {
+		t.Fatal("Expected match")
+	}
+	if positions[0] != 10 {
+		t.Errorf("Expected match at word start 10, got %v", positions)
+	}
+}
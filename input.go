@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bhelper/feature"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// maxInputFileSize bounds the files that can be loaded as input
+	maxInputFileSize = 1 << 20
+
+	defaultTextAreaHeight = 6
+	maxTextAreaHeight     = 12
+)
+
+// newTextArea creates the editor used by multi-line features
+func newTextArea() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "Type or paste your input..."
+	ta.MaxHeight = 0
+	ta.CharLimit = 0
+	ta.SetWidth(defaultInputWidth)
+	ta.SetHeight(defaultTextAreaHeight)
+	return ta
+}
+
+// multiline reports whether the selected feature takes multi-line input
+func (c CLI) multiline() bool {
+	return c.selectedFeature != nil && feature.IsMultiline(c.selectedFeature)
+}
+
+// inputValue returns the current input of the selected feature
+func (c CLI) inputValue() string {
+	if c.multiline() {
+		return c.textArea.Value()
+	}
+	return c.textInput.Value()
+}
+
+// setInput replaces the input and moves the cursor to its end
+func (c *CLI) setInput(value string) {
+	if c.multiline() {
+		c.textArea.SetValue(value)
+		return
+	}
+	c.textInput.SetValue(value)
+	c.textInput.CursorEnd()
+}
+
+// focusInput gives the keyboard focus to the input
+func (c *CLI) focusInput() tea.Cmd {
+	if c.multiline() {
+		return c.textArea.Focus()
+	}
+	return c.textInput.Focus()
+}
+
+// blurInput takes the keyboard focus away from the input
+func (c *CLI) blurInput() {
+	c.textInput.Blur()
+	c.textArea.Blur()
+}
+
+// updateInput passes a key to the input
+func (c CLI) updateInput(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	var cmd tea.Cmd
+	if c.multiline() {
+		c.textArea, cmd = c.textArea.Update(msg)
+	} else {
+		c.textInput, cmd = c.textInput.Update(msg)
+	}
+	return c, cmd
+}
+
+// atFirstLine and atLastLine report whether the cursor is on the first or
+// last line of the input, where up and down browse the history instead of
+// moving between lines
+func (c CLI) atFirstLine() bool {
+	return !c.multiline() || c.textArea.Line() == 0
+}
+
+func (c CLI) atLastLine() bool {
+	return !c.multiline() || c.textArea.Line() == c.textArea.LineCount()-1
+}
+
+// renderInput shows the input with its label, followed by the problem
+// found by the feature's validator if any
+func (c CLI) renderInput() string {
+	label := c.styles.label.Render("Input: ")
+	if c.multiline() {
+		return label + "\n" + c.textArea.View() + c.renderValidation(0)
+	}
+	return label + c.textInput.View() + c.renderValidation(lipgloss.Width(label))
+}
+
+// validateInput checks the current input, empty input is never reported
+func (c CLI) validateInput() error {
+	input := c.inputValue()
+	if c.selectedFeature == nil || input == "" {
+		return nil
+	}
+	return feature.Validate(c.selectedFeature, input)
+}
+
+// renderValidation underlines the invalid part of a single-line input that
+// starts at the given column, and shows the problem with a hint
+func (c CLI) renderValidation(indent int) string {
+	err := c.validateInput()
+	if err == nil {
+		return ""
+	}
+
+	ie, ok := feature.AsInputError(err)
+	if !ok {
+		return "\n" + c.styles.invalid.Render("✗ "+err.Error())
+	}
+
+	var s strings.Builder
+	// The marker only lines up while the input is not scrolled sideways
+	if !c.multiline() && lipgloss.Width(c.inputValue()) < c.textInput.Width {
+		_, marker := ie.Context()
+		s.WriteString("\n" + strings.Repeat(" ", indent+lipgloss.Width(c.textInput.Prompt)) + c.styles.invalid.Render(marker))
+	}
+
+	s.WriteString("\n" + c.styles.invalid.Render("✗ "+ie.Error()))
+	if ie.Suggestion != "" {
+		s.WriteString(c.styles.help.Render(" • " + ie.Suggestion))
+	}
+	return s.String()
+}
+
+// openLoadFile shows the prompt for a file to load as input
+func (c CLI) openLoadFile() (CLI, tea.Cmd) {
+	c.loadingFile = true
+	c.loadPath.SetValue("")
+	c.blurInput()
+	return c, c.loadPath.Focus()
+}
+
+// closeLoadFile hides the file prompt and returns focus to the input
+func (c CLI) closeLoadFile() (CLI, tea.Cmd) {
+	c.loadingFile = false
+	c.loadPath.Blur()
+	return c, c.focusInput()
+}
+
+// updateLoadFile handles typing the path of the file to load
+func (c CLI) updateLoadFile(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Back):
+		return c.closeLoadFile()
+
+	case key.Matches(msg, c.keys.Execute):
+		path := strings.TrimSpace(c.loadPath.Value())
+		if path == "" {
+			c.status = "Enter a file name"
+			return c, nil
+		}
+
+		input, err := readInputFile(path)
+		if err != nil {
+			c.status = err.Error()
+			return c, nil
+		}
+
+		c, focus := c.closeLoadFile()
+		if !c.multiline() && strings.Contains(input, "\n") {
+			c.status = fmt.Sprintf("Loaded %s, line breaks were replaced since %s takes a single line", path, c.selectedFeature.Name())
+		} else {
+			c.status = fmt.Sprintf("Loaded %s", path)
+		}
+
+		c, cmd := c.replaceInput(input)
+		return c, tea.Batch(focus, cmd)
+	}
+
+	var cmd tea.Cmd
+	c.loadPath, cmd = c.loadPath.Update(msg)
+	return c, cmd
+}
+
+// readInputFile reads a file to use as input, dropping the final newline
+func readInputFile(path string) (string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to load input: %w", err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("failed to load input: %s is a directory", path)
+	}
+	if info.Size() > maxInputFileSize {
+		return "", fmt.Errorf("failed to load input: %s is larger than %d KiB", path, maxInputFileSize>>10)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to load input: %w", err)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+}
+
+// renderLoadFile shows the file prompt
+func (c CLI) renderLoadFile() string {
+	return c.styles.label.Render("Load input from: ") + c.loadPath.View() + "\n"
+}
+
+// oneLine shows a possibly multi-line input on a single line
+func oneLine(s string) string {
+	return strings.ReplaceAll(s, "\n", "↵")
+}
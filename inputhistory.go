@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recordInput adds an executed input to the persistent history of the
+// selected feature
+func (c *CLI) recordInput(input string) {
+	if c.inputHistory == nil {
+		return
+	}
+
+	c.inputHistory.Add(c.selectedFeature.ID(), input)
+	if err := c.inputHistory.Save(); err != nil {
+		c.status = fmt.Sprintf("History not saved: %v", err)
+	}
+	c.historyIndex = -1
+}
+
+// browseHistory replaces the input with an older (delta < 0) or newer
+// (delta > 0) history entry, restoring the typed draft past the newest one
+func (c CLI) browseHistory(delta int) (CLI, tea.Cmd) {
+	if c.inputHistory == nil {
+		return c, nil
+	}
+
+	entries := c.inputHistory.Entries(c.selectedFeature.ID())
+	if len(entries) == 0 {
+		return c, nil
+	}
+
+	index := c.historyIndex
+	if index == -1 {
+		if delta > 0 {
+			return c, nil
+		}
+		c.historyDraft = c.inputValue()
+		index = len(entries)
+	}
+
+	index += delta
+	switch {
+	case index < 0:
+		return c, nil
+	case index >= len(entries):
+		c.historyIndex = -1
+		return c.replaceInput(c.historyDraft)
+	}
+
+	c.historyIndex = index
+	return c.replaceInput(entries[index])
+}
+
+// replaceInput sets the input to value, keeping the change undoable
+func (c CLI) replaceInput(value string) (CLI, tea.Cmd) {
+	oldValue := c.inputValue()
+	if oldValue == value {
+		return c, nil
+	}
+
+	c.history.Push(oldValue)
+	c.setInput(value)
+	return c.inputChanged()
+}
+
+// startSearch enters reverse incremental search over the input history
+func (c CLI) startSearch() (CLI, tea.Cmd) {
+	if c.inputHistory == nil {
+		return c, nil
+	}
+
+	c.searching = true
+	c.searchQuery = ""
+	c.searchIndex = -1
+	return c, nil
+}
+
+// updateSearch handles keys while reverse searching the input history
+func (c CLI) updateSearch(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	id := c.selectedFeature.ID()
+
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Back), msg.Type == tea.KeyCtrlG:
+		c.searching = false
+		return c, nil
+
+	case key.Matches(msg, c.keys.HistorySearch):
+		// Jump to the next older match
+		before := c.searchIndex
+		if before < 0 {
+			before = len(c.inputHistory.Entries(id))
+		}
+		if i, ok := c.inputHistory.Search(id, c.searchQuery, before); ok {
+			c.searchIndex = i
+		}
+		return c, nil
+
+	case msg.Type == tea.KeyEnter, msg.Type == tea.KeyTab, msg.Type == tea.KeyRight:
+		c.searching = false
+		if c.searchIndex >= 0 {
+			c.historyIndex = c.searchIndex
+			return c.replaceInput(c.inputHistory.Entries(id)[c.searchIndex])
+		}
+		return c, nil
+
+	case msg.Type == tea.KeyBackspace:
+		if c.searchQuery != "" {
+			runes := []rune(c.searchQuery)
+			c.searchQuery = string(runes[:len(runes)-1])
+		}
+
+	case msg.Type == tea.KeyRunes, msg.Type == tea.KeySpace:
+		c.searchQuery += string(msg.Runes)
+
+	default:
+		return c, nil
+	}
+
+	c.searchIndex, _ = c.inputHistory.Search(id, c.searchQuery, len(c.inputHistory.Entries(id)))
+	return c, nil
+}
+
+// renderSearch shows the reverse search prompt and its current match
+func (c CLI) renderSearch() string {
+	match := ""
+	if c.searchIndex >= 0 {
+		match = c.inputHistory.Entries(c.selectedFeature.ID())[c.searchIndex]
+	}
+
+	prompt := fmt.Sprintf("(reverse-i-search)`%s': ", c.searchQuery)
+	if c.searchQuery != "" && c.searchIndex < 0 {
+		prompt = fmt.Sprintf("(failed reverse-i-search)`%s': ", c.searchQuery)
+	}
+	return c.styles.label.Render(prompt) + oneLine(match)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyMap holds the configurable key bindings of the TUI
+type keyMap struct {
+	ForceQuit        key.Binding
+	Quit             key.Binding
+	Back             key.Binding
+	Up               key.Binding
+	Down             key.Binding
+	Select           key.Binding
+	Filter           key.Binding
+	Pin              key.Binding
+	Help             key.Binding
+	Execute          key.Binding
+	ExecuteMultiline key.Binding
+	FeatureHelp      key.Binding
+	Undo             key.Binding
+	Redo             key.Binding
+	OutputFormat     key.Binding
+	HistoryPrev      key.Binding
+	HistoryNext      key.Binding
+	HistorySearch    key.Binding
+	Presets          key.Binding
+	PresetSave       key.Binding
+	PresetDelete     key.Binding
+	LoadFile         key.Binding
+	FocusOutput      key.Binding
+	Complete         key.Binding
+	PageUp           key.Binding
+	PageDown         key.Binding
+	Copy             key.Binding
+	Save             key.Binding
+	Send             key.Binding
+	Compare          key.Binding
+	NewTab           key.Binding
+	NextTab          key.Binding
+	PrevTab          key.Binding
+	CloseTab         key.Binding
+	Theme            key.Binding
+}
+
+// defaultKeyMap returns the built-in key bindings
+func defaultKeyMap() keyMap {
+	return keyMap{
+		ForceQuit: newBinding("quit", "ctrl+c"),
+		Quit:      newBinding("quit", "q"),
+		Back:      newBinding("back", "esc"),
+		Up:        newBinding("up", "up", "k"),
+		Down:      newBinding("down", "down", "j"),
+		Select:    newBinding("select", "enter"),
+		Filter:    newBinding("filter", "/"),
+		Pin:       newBinding("pin", "f"),
+		Help:      newBinding("help", "h", "?"),
+		Execute:   newBinding("execute", "enter"),
+		// Most terminals send Ctrl+Enter as Ctrl+J
+		ExecuteMultiline: newBinding("execute", "ctrl+j", "alt+enter"),
+		FeatureHelp:      newBinding("help", "ctrl+h"),
+		Undo:             newBinding("undo", "ctrl+z"),
+		Redo:             newBinding("redo", "ctrl+y"),
+		OutputFormat:     newBinding("output format", "ctrl+o"),
+		HistoryPrev:      newBinding("history", "up"),
+		HistoryNext:      newBinding("history", "down"),
+		HistorySearch:    newBinding("search history", "ctrl+r"),
+		Presets:          newBinding("presets", "ctrl+p"),
+		PresetSave:       newBinding("save current input", "s"),
+		PresetDelete:     newBinding("delete", "d", "delete"),
+		LoadFile:         newBinding("load file", "ctrl+l"),
+		FocusOutput:      newBinding("scroll output", "tab"),
+		Complete:         newBinding("complete", "tab"),
+		PageUp:           newBinding("page up", "pgup"),
+		PageDown:         newBinding("page down", "pgdown"),
+		Copy:             newBinding("copy", "alt+c"),
+		Save:             newBinding("save", "ctrl+s"),
+		Send:             newBinding("send to feature", "ctrl+x"),
+		Compare:          newBinding("compare", "ctrl+g"),
+		NewTab:           newBinding("new tab", "ctrl+t"),
+		// Few terminals tell Ctrl+Tab apart from Tab, Ctrl+PgDn works in all
+		NextTab:  newBinding("next tab", "ctrl+tab", "ctrl+pgdown"),
+		PrevTab:  newBinding("previous tab", "ctrl+pgup"),
+		CloseTab: newBinding("close tab", "alt+w"),
+		Theme:    newBinding("theme", "f2"),
+	}
+}
+
+// newBinding creates a binding whose help label is derived from its keys
+func newBinding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys), desc))
+}
+
+// actions maps configuration names to bindings
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"force_quit":        &k.ForceQuit,
+		"quit":              &k.Quit,
+		"back":              &k.Back,
+		"up":                &k.Up,
+		"down":              &k.Down,
+		"select":            &k.Select,
+		"filter":            &k.Filter,
+		"pin":               &k.Pin,
+		"help":              &k.Help,
+		"execute":           &k.Execute,
+		"execute_multiline": &k.ExecuteMultiline,
+		"feature_help":      &k.FeatureHelp,
+		"undo":              &k.Undo,
+		"redo":              &k.Redo,
+		"output_format":     &k.OutputFormat,
+		"history_prev":      &k.HistoryPrev,
+		"history_next":      &k.HistoryNext,
+		"history_search":    &k.HistorySearch,
+		"presets":           &k.Presets,
+		"preset_save":       &k.PresetSave,
+		"preset_delete":     &k.PresetDelete,
+		"load_file":         &k.LoadFile,
+		"focus_output":      &k.FocusOutput,
+		"complete":          &k.Complete,
+		"page_up":           &k.PageUp,
+		"page_down":         &k.PageDown,
+		"copy":              &k.Copy,
+		"save":              &k.Save,
+		"send":              &k.Send,
+		"compare":           &k.Compare,
+		"new_tab":           &k.NewTab,
+		"next_tab":          &k.NextTab,
+		"prev_tab":          &k.PrevTab,
+		"close_tab":         &k.CloseTab,
+		"theme":             &k.Theme,
+	}
+}
+
+// apply overrides bindings with those from the configuration, reporting
+// every unknown action at once
+func (k *keyMap) apply(bindings map[string][]string) error {
+	actions := k.actions()
+
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		b, ok := actions[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("keys.%s: unknown action", name))
+			continue
+		}
+
+		keys := bindings[name]
+		b.SetKeys(keys...)
+		b.SetHelp(keyLabel(keys), b.Help().Desc)
+	}
+	return errors.Join(errs...)
+}
+
+// keyLabel renders keys the way they are shown in help lines
+func keyLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		switch k {
+		case "up":
+			labels[i] = "↑"
+		case "down":
+			labels[i] = "↓"
+		default:
+			labels[i] = strings.ToUpper(k)
+		}
+	}
+	return strings.Join(labels, "/")
+}
+
+// helpLine renders bindings as "KEY: description" pairs
+func (st styles) helpLine(bindings ...key.Binding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if !b.Enabled() {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", b.Help().Key, b.Help().Desc))
+	}
+	return st.help.Render(strings.Join(parts, " • "))
+}
+
+// withDesc returns a copy of a binding with a different help description
+func withDesc(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// navHelp renders an up/down pair as a single "navigate" hint
+func navHelp(up, down key.Binding) key.Binding {
+	label := firstKeyLabel(up) + "/" + firstKeyLabel(down)
+	return key.NewBinding(key.WithKeys(up.Keys()...), key.WithHelp(label, "navigate"))
+}
+
+func firstKeyLabel(b key.Binding) string {
+	if len(b.Keys()) == 0 {
+		return ""
+	}
+	return keyLabel(b.Keys()[:1])
+}
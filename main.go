@@ -4,6 +4,8 @@ import (
 	"bhelper/feature"
 	"bhelper/feature/collision"
 	"bhelper/feature/time"
+	"bhelper/recording"
+	"bhelper/store"
 	"fmt"
 	"os"
 
@@ -13,17 +15,128 @@ import (
 func main() {
 	// Register all features
 	registry := feature.NewFeatureRegistry()
-	registry.Register(feature.NewCharacterAnalyzer())
-	registry.Register(feature.NewTimezoneAnalyzer())
-	registry.Register(time.NewTimeConverter())
-	registry.Register(collision.NewCollisionAnalyzer())
-	// registry.Register(NewWeatherForecast())
-	// ... register 100 features here
+	for _, f := range []feature.Feature{
+		feature.NewCharacterAnalyzer(),
+		feature.NewTimezoneAnalyzer(),
+		time.NewTimeConverter(),
+		collision.NewCollisionAnalyzer(),
+		// NewWeatherForecast(),
+		// ... register 100 features here
+	} {
+		if err := registry.Register(f); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+
+	flags, args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n%s", err, usageText)
+		os.Exit(exitUsage)
+	}
+
+	settings, err := loadSettings(registry, flags.config, len(args) == 0 || needsPlugins(args))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	for _, warning := range settings.warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", warning)
+	}
+
+	// Presets and history are conveniences, bhelper still works when they cannot be loaded
+	presets := store.NewPresets("")
+	if path, err := store.DefaultPresetsPath(); err == nil {
+		if presets, err = store.LoadPresets(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	historySize := settings.config.UI.HistorySize
+	inputHistory := store.NewInputHistory("", historySize)
+	if path, err := store.DefaultHistoryPath(); err == nil {
+		if inputHistory, err = store.LoadInputHistory(path, historySize); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	// Usage statistics stay local and can be turned off in the config
+	var usage *store.Usage
+	if settings.config.TrackUsage {
+		usage = store.NewUsage("")
+		if path, err := store.DefaultUsagePath(); err == nil {
+			if usage, err = store.LoadUsage(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+
+	// Runs are logged for replay only when asked for with --record
+	var recorder *recording.Recorder
+	if flags.record != "" {
+		if recorder, err = recording.Open(flags.record); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		defer recorder.Close()
+	}
+
+	// Run a non-interactive subcommand when arguments are given
+	if len(args) > 0 {
+		r := &commandRunner{
+			registry: registry,
+			timeouts: settings.timeouts,
+			presets:  presets,
+			usage:    usage,
+			recorder: recorder,
+			order:    feature.SortOrder(settings.config.UI.FeatureOrder),
+			stdin:    os.Stdin,
+			stdout:   os.Stdout,
+			stderr:   os.Stderr,
+		}
+		code := r.execute(args)
+		// os.Exit skips the deferred close
+		if recorder != nil {
+			recorder.Close()
+		}
+		os.Exit(code)
+	}
+
+	opts := settings.cliOptions()
+	opts.inputHistory = inputHistory
+	opts.presets = presets
+	opts.usage = usage
+	opts.recorder = recorder
+
+	// The tabs of the last session are reopened unless turned off in the config
+	var session *store.Session
+	if settings.config.UI.RestoreTabs {
+		session = store.NewSession("")
+		if path, err := store.DefaultSessionPath(); err == nil {
+			if session, err = store.LoadSession(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+	opts.session = session
+
+	// The clipboard is written through the same output the interface is
+	// drawn to
+	out := newTerminalOutput(os.Stdout)
+	opts.terminal = out
 
 	// Start CLI with all registered features
-	p := tea.NewProgram(NewCLI(registry))
-	if _, err := p.Run(); err != nil {
+	p := tea.NewProgram(NewCLI(registry, opts), tea.WithOutput(out), tea.WithMouseCellMotion())
+	model, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+
+	if cli, ok := model.(CLI); ok && session != nil {
+		session.Set(cli.sessionTabs())
+		if err := session.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
 }
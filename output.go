@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// outputFrameWidth and outputFrameHeight are the space taken by the
+	// border and padding of the result box
+	outputFrameWidth  = 6
+	outputFrameHeight = 4
+
+	minOutputWidth  = 10
+	minOutputHeight = 3
+
+	// defaultInputWidth is used until the terminal size is known
+	defaultInputWidth = 70
+)
+
+// resize adapts the view to a new terminal size
+func (c CLI) resize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
+	c.width = msg.Width
+	c.height = msg.Height
+	c.sizeInputs()
+	for i := range c.compare {
+		c.compare[i].input.Width = c.compareInputWidth()
+	}
+	return c, nil
+}
+
+// sizeInputs fits the inputs of the tab in front to the window
+func (c *CLI) sizeInputs() {
+	// Leave room for the "Input: " label and the prompt
+	width := max(c.width-lipgloss.Width("Input: ")-lipgloss.Width(c.textInput.Prompt)-1, minOutputWidth)
+	if c.inputWidth > 0 {
+		width = min(width, c.inputWidth)
+	}
+	c.textInput.Width = width
+	c.textArea.SetWidth(width + lipgloss.Width(c.textInput.Prompt))
+	c.textArea.SetHeight(min(max(c.height/4, minOutputHeight), maxTextAreaHeight))
+}
+
+// layoutOutput wraps the output to the window width, highlights search
+// matches and sizes the viewport to the height left between header and
+// footer
+func (c *CLI) layoutOutput(header, footer string) {
+	content := c.output
+	if c.width > 0 {
+		c.viewport.Width = max(c.width-outputFrameWidth, minOutputWidth)
+		content = lipgloss.NewStyle().Width(c.viewport.Width).Render(content)
+	} else {
+		c.viewport.Width = lipgloss.Width(content)
+	}
+
+	lines := strings.Split(content, "\n")
+	c.outputMatches = c.highlightMatches(lines)
+	if c.outputMatch >= len(c.outputMatches) {
+		c.outputMatch = 0
+	}
+
+	c.viewport.Height = len(lines)
+	if c.height > 0 {
+		used := strings.Count(header, "\n") + strings.Count(footer, "\n") + outputFrameHeight + 2
+		c.viewport.Height = min(len(lines), max(c.height-used, minOutputHeight))
+	}
+	c.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// highlightMatches emphasizes occurrences of the output search query and
+// returns the indexes of the matching lines. Matching lines lose their
+// other styling.
+func (c CLI) highlightMatches(lines []string) []int {
+	query := strings.ToLower(c.outputQuery.Value())
+	if query == "" || c.outputSearching {
+		return nil
+	}
+
+	var matches []int
+	for i, line := range lines {
+		plain := ansi.Strip(line)
+		lower := strings.ToLower(plain)
+		if !strings.Contains(lower, query) {
+			continue
+		}
+		matches = append(matches, i)
+
+		// Case folding may change byte offsets, highlight the whole line then
+		if len(lower) != len(plain) {
+			lines[i] = c.styles.match.Render(plain)
+			continue
+		}
+
+		var b strings.Builder
+		for {
+			at := strings.Index(lower, query)
+			if at < 0 {
+				b.WriteString(plain)
+				break
+			}
+			end := at + len(query)
+			b.WriteString(plain[:at] + c.styles.match.Render(plain[at:end]))
+			plain, lower = plain[end:], lower[end:]
+		}
+		lines[i] = b.String()
+	}
+	return matches
+}
+
+// relayout refreshes the viewport before it is scrolled from Update
+func (c *CLI) relayout() {
+	c.layoutOutput(c.renderExecuteHeader(), c.renderExecuteFooter())
+}
+
+// scrollOutput applies a scroll action to the result viewport
+func (c CLI) scrollOutput(scroll func(*CLI)) (CLI, tea.Cmd) {
+	if c.output == "" {
+		return c, nil
+	}
+
+	c.relayout()
+	scroll(&c)
+	return c, nil
+}
+
+// updateOutputMouse scrolls the result with the mouse wheel
+func (c CLI) updateOutputMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if c.mode != ModeFeatureExecute || c.output == "" {
+		return c, nil
+	}
+
+	c.relayout()
+	var cmd tea.Cmd
+	c.viewport, cmd = c.viewport.Update(msg)
+	return c, cmd
+}
+
+// focusOutput moves the keyboard focus from the input to the result
+func (c CLI) focusOutput() (CLI, tea.Cmd) {
+	if c.output == "" {
+		return c, nil
+	}
+
+	c.outputFocused = true
+	c.blurInput()
+	return c, nil
+}
+
+// blurOutput returns the keyboard focus to the input
+func (c CLI) blurOutput() (CLI, tea.Cmd) {
+	c.outputFocused = false
+	c.outputSearching = false
+	c.outputQuery.Blur()
+	return c, c.focusInput()
+}
+
+// updateOutput handles keys while the result has the focus, pager style
+func (c CLI) updateOutput(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	if c.outputSearching {
+		return c.updateOutputSearch(msg)
+	}
+	if c.output == "" {
+		return c.blurOutput()
+	}
+
+	// The pager letters follow less and are not configurable
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Back, c.keys.FocusOutput):
+		return c.blurOutput()
+
+	case key.Matches(msg, c.keys.Up):
+		return c.scrollOutput(func(c *CLI) { c.viewport.ScrollUp(1) })
+
+	case key.Matches(msg, c.keys.Down):
+		return c.scrollOutput(func(c *CLI) { c.viewport.ScrollDown(1) })
+
+	case key.Matches(msg, c.keys.PageUp), msg.String() == "b":
+		return c.scrollOutput(func(c *CLI) { c.viewport.PageUp() })
+
+	case key.Matches(msg, c.keys.PageDown), msg.String() == " ", msg.String() == "f":
+		return c.scrollOutput(func(c *CLI) { c.viewport.PageDown() })
+
+	case msg.String() == "g", msg.Type == tea.KeyHome:
+		return c.scrollOutput(func(c *CLI) { c.viewport.GotoTop() })
+
+	case msg.String() == "G", msg.Type == tea.KeyEnd:
+		return c.scrollOutput(func(c *CLI) { c.viewport.GotoBottom() })
+
+	case key.Matches(msg, c.keys.Filter):
+		c.outputSearching = true
+		c.outputQuery.SetValue("")
+		c.outputQuery.Focus()
+		return c, textinput.Blink
+
+	case msg.String() == "n":
+		return c.jumpToMatch(c.outputMatch + 1)
+
+	case msg.String() == "N":
+		return c.jumpToMatch(c.outputMatch - 1)
+	}
+
+	return c, nil
+}
+
+// updateOutputSearch handles typing a query to search the result for
+func (c CLI) updateOutputSearch(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Back):
+		c.outputSearching = false
+		c.outputQuery.Blur()
+		c.outputQuery.SetValue("")
+		return c, nil
+
+	case key.Matches(msg, c.keys.Select):
+		c.outputSearching = false
+		c.outputQuery.Blur()
+		return c.jumpToMatch(0)
+	}
+
+	var cmd tea.Cmd
+	c.outputQuery, cmd = c.outputQuery.Update(msg)
+	return c, cmd
+}
+
+// jumpToMatch scrolls to the i-th line matching the search query, wrapping
+// around at both ends
+func (c CLI) jumpToMatch(i int) (CLI, tea.Cmd) {
+	c.relayout()
+	if len(c.outputMatches) == 0 {
+		if c.outputQuery.Value() != "" {
+			c.status = fmt.Sprintf("No match for %q", c.outputQuery.Value())
+		}
+		return c, nil
+	}
+
+	c.outputMatch = (i%len(c.outputMatches) + len(c.outputMatches)) % len(c.outputMatches)
+	c.viewport.SetYOffset(c.outputMatches[c.outputMatch])
+	return c, nil
+}
+
+// renderOutputHeading shows the result label, format and search state
+func (c CLI) renderOutputHeading() string {
+	heading := c.styles.section.Render("Result:") + " " + c.styles.help.Render(string(c.format))
+
+	switch {
+	case c.outputSearching:
+		heading += "  " + c.outputQuery.View()
+	case len(c.outputMatches) > 0:
+		heading += "  " + c.styles.help.Render(fmt.Sprintf("/%s (%d/%d)", c.outputQuery.Value(), c.outputMatch+1, len(c.outputMatches)))
+	}
+
+	if c.viewport.Height > 0 && c.viewport.TotalLineCount() > c.viewport.Height {
+		heading += "  " + c.styles.help.Render(fmt.Sprintf("%3.f%%", c.viewport.ScrollPercent()*100))
+	}
+	return heading
+}
+
+// renderOutputBox shows the visible part of the result in its box
+func (c CLI) renderOutputBox() string {
+	style := c.styles.outputBox
+	if c.outputFocused {
+		style = style.BorderForeground(c.styles.selectedFeature.GetForeground())
+	}
+	return style.Render(c.viewport.View())
+}
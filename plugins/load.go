@@ -0,0 +1,70 @@
+package plugins
+
+import (
+	"bhelper/store"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dir is the name of the plugins directory in the config directory
+const Dir = "plugins"
+
+// DefaultDir returns the plugins location in the config directory
+func DefaultDir() (string, error) {
+	dir, err := store.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, Dir), nil
+}
+
+// LoadDir loads every executable in dir in name order. Hidden files and
+// non-executable files are ignored. A plugin that fails to describe itself
+// is reported in the returned errors and does not prevent the others from
+// loading. A missing directory holds no plugins.
+func LoadDir(ctx context.Context, dir string) ([]*Plugin, []error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to read plugins: %w", err)}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var loaded []*Plugin
+	var errs []error
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") || !isExecutable(dir, entry) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		p, err := Load(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if other, ok := seen[p.ID()]; ok {
+			errs = append(errs, fmt.Errorf("plugin %s: id %q is already used by %s", path, p.ID(), other))
+			continue
+		}
+		seen[p.ID()] = path
+		loaded = append(loaded, p)
+	}
+	return loaded, errs
+}
+
+// isExecutable reports whether a directory entry is a regular executable
+// file, following symlinks
+func isExecutable(dir string, entry os.DirEntry) bool {
+	info, err := os.Stat(filepath.Join(dir, entry.Name()))
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
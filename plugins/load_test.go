@@ -0,0 +1,61 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "b-upper", describeUpper, "")
+	writePlugin(t, dir, "a-lower", `{"protocol": 1, "id": "lower"}`, "")
+	writePlugin(t, dir, "c-again", describeUpper, "")
+	writePlugin(t, dir, "d-broken", `{`, "")
+	writePlugin(t, dir, ".hidden", `{`, "")
+
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, errs := LoadDir(context.Background(), dir)
+
+	var ids []string
+	for _, p := range loaded {
+		ids = append(ids, p.ID())
+	}
+	if strings.Join(ids, ",") != "lower,upper" {
+		t.Errorf("Expected lower and upper in name order, got %v", ids)
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), `id "upper" is already used`) {
+		t.Errorf("Expected duplicate ID error, got %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Error(), "d-broken") {
+		t.Errorf("Expected error naming the broken plugin, got %v", errs[1])
+	}
+}
+
+func TestLoadDirMissing(t *testing.T) {
+	loaded, errs := LoadDir(context.Background(), filepath.Join(t.TempDir(), "none"))
+	if loaded != nil || errs != nil {
+		t.Errorf("Expected no plugins and no error, got %v, %v", loaded, errs)
+	}
+}
+
+func TestLoadDefaultName(t *testing.T) {
+	path := writePlugin(t, t.TempDir(), "lower", `{"protocol": 1, "id": "lower"}`, "")
+
+	p, err := Load(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if p.Name() != "lower" || p.Category() != "Plugins" || !strings.Contains(p.Help(), path) {
+		t.Errorf("Expected defaults, got name %q category %q help %q", p.Name(), p.Category(), p.Help())
+	}
+}
@@ -0,0 +1,300 @@
+// Package plugins runs features implemented by external executables.
+//
+// A plugin is an executable that answers two commands:
+//
+//	<plugin> describe            prints a Description as JSON
+//	<plugin> execute             reads an ExecuteRequest as JSON on stdin and
+//	                             prints a Response as JSON
+//
+// The process is started anew for every call, so plugins can be written in
+// any language and keep no state between runs.
+package plugins
+
+import (
+	"bhelper/feature"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// Protocol is the protocol version spoken by this package
+	Protocol = 1
+
+	// describeTimeout bounds the describe call made at startup
+	describeTimeout = 5 * time.Second
+
+	// maxResponseSize bounds what a plugin may print on stdout
+	maxResponseSize = 4 << 20
+
+	// maxStderrSize bounds the stderr kept to explain a failure
+	maxStderrSize = 4 << 10
+
+	// waitDelay is how long a killed plugin gets to release its output
+	waitDelay = time.Second
+)
+
+// validID matches IDs usable on the command line and in pipelines
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Description is the answer to the describe command
+type Description struct {
+	Protocol    int       `json:"protocol"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Help        string    `json:"help"`
+	Category    string    `json:"category,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Examples    []Example `json:"examples,omitempty"`
+	Outputs     []Output  `json:"outputs,omitempty"`
+	Multiline   bool      `json:"multiline,omitempty"`
+	// Timeout is a duration such as "10s", empty for the default timeout
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Example is a usage example of a plugin
+type Example struct {
+	Input       string `json:"input"`
+	Description string `json:"description"`
+}
+
+// Output is a named result field of a plugin
+type Output struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+// ExecuteRequest is written to the stdin of the execute command
+type ExecuteRequest struct {
+	Input string `json:"input"`
+}
+
+// Response is the answer to the execute command: either a result or an
+// error message for invalid input
+type Response struct {
+	Result *feature.Result `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Plugin adapts an executable to feature.Feature
+type Plugin struct {
+	path    string
+	desc    Description
+	timeout time.Duration
+}
+
+// Load asks the executable at path to describe itself
+func Load(ctx context.Context, path string) (*Plugin, error) {
+	ctx, cancel := context.WithTimeout(ctx, describeTimeout)
+	defer cancel()
+
+	var desc Description
+	if err := call(ctx, path, "describe", nil, &desc); err != nil {
+		return nil, fmt.Errorf("plugin %s: describe: %w", path, err)
+	}
+
+	p := &Plugin{path: path, desc: desc}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// validate checks the description of the plugin
+func (p *Plugin) validate() error {
+	if p.desc.Protocol != Protocol {
+		return fmt.Errorf("unsupported protocol version %d (expected %d)", p.desc.Protocol, Protocol)
+	}
+	if !validID.MatchString(p.desc.ID) {
+		return fmt.Errorf("invalid id %q: use lowercase letters, digits, '-' and '_'", p.desc.ID)
+	}
+	if p.desc.Name == "" {
+		p.desc.Name = p.desc.ID
+	}
+	if p.desc.Timeout != "" {
+		d, err := time.ParseDuration(p.desc.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q", p.desc.Timeout)
+		}
+		p.timeout = d
+	}
+	return nil
+}
+
+// Path returns the location of the executable
+func (p *Plugin) Path() string {
+	return p.path
+}
+
+// ID returns the unique identifier declared by the plugin
+func (p *Plugin) ID() string {
+	return p.desc.ID
+}
+
+// Name returns display name
+func (p *Plugin) Name() string {
+	return p.desc.Name
+}
+
+// Description returns short description
+func (p *Plugin) Description() string {
+	return p.desc.Description
+}
+
+// Help returns detailed help text
+func (p *Plugin) Help() string {
+	if p.desc.Help == "" {
+		return fmt.Sprintf("%s is provided by the plugin %s.", p.desc.Name, p.path)
+	}
+	return p.desc.Help
+}
+
+// Examples returns usage examples
+func (p *Plugin) Examples() []feature.Example {
+	examples := make([]feature.Example, len(p.desc.Examples))
+	for i, ex := range p.desc.Examples {
+		examples[i] = feature.Example{Input: ex.Input, Description: ex.Description}
+	}
+	return examples
+}
+
+// Category returns the category declared by the plugin, "Plugins" by default
+func (p *Plugin) Category() string {
+	if p.desc.Category == "" {
+		return "Plugins"
+	}
+	return p.desc.Category
+}
+
+// Tags returns additional search keywords
+func (p *Plugin) Tags() []string {
+	return append([]string{"plugin"}, p.desc.Tags...)
+}
+
+// Outputs returns the named result fields
+func (p *Plugin) Outputs() []feature.Output {
+	outputs := make([]feature.Output, len(p.desc.Outputs))
+	for i, out := range p.desc.Outputs {
+		outputs[i] = feature.Output{Key: out.Key, Description: out.Description}
+	}
+	return outputs
+}
+
+// Multiline reports whether the plugin takes multi-line input
+func (p *Plugin) Multiline() bool {
+	return p.desc.Multiline
+}
+
+// Timeout returns the timeout declared by the plugin
+func (p *Plugin) Timeout() time.Duration {
+	if p.timeout > 0 {
+		return p.timeout
+	}
+	return feature.DefaultTimeout
+}
+
+// Execute runs the plugin and returns its result as text
+func (p *Plugin) Execute(input string) (string, error) {
+	result, err := p.ExecuteResult(input)
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// ExecuteResult runs the plugin within its own timeout
+func (p *Plugin) ExecuteResult(input string) (*feature.Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout())
+	defer cancel()
+	return p.ExecuteContext(ctx, input, nil)
+}
+
+// ExecuteContext runs the plugin, killing it when ctx is done
+func (p *Plugin) ExecuteContext(ctx context.Context, input string, progress feature.ProgressFunc) (*feature.Result, error) {
+	var resp Response
+	if err := call(ctx, p.path, "execute", ExecuteRequest{Input: input}, &resp); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("plugin %s: %w", p.desc.ID, err)
+	}
+
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if resp.Result == nil {
+		return nil, fmt.Errorf("plugin %s: response has neither a result nor an error", p.desc.ID)
+	}
+	for i, s := range resp.Result.Sections {
+		if s == nil {
+			return nil, fmt.Errorf("plugin %s: malformed response: section %d is null", p.desc.ID, i+1)
+		}
+	}
+	return resp.Result, nil
+}
+
+// call runs a plugin command, writing req as JSON to its stdin and decoding
+// its stdout into resp
+func call(ctx context.Context, path, command string, req, resp any) error {
+	cmd := exec.CommandContext(ctx, path, command)
+	cmd.WaitDelay = waitDelay
+
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		cmd.Stdin = bytes.NewReader(data)
+	}
+
+	stdout := &limitedBuffer{limit: maxResponseSize}
+	stderr := &limitedBuffer{limit: maxStderrSize}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	if stdout.truncated {
+		return fmt.Errorf("response is larger than %d MiB", maxResponseSize>>20)
+	}
+
+	if err := json.NewDecoder(stdout).Decode(resp); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("empty response")
+		}
+		return fmt.Errorf("malformed response: %w", err)
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest, so a misbehaving plugin cannot exhaust memory
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); len(p) > room {
+		b.Buffer.Write(p[:max(room, 0)])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
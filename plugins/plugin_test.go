@@ -0,0 +1,140 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const describeUpper = `{"protocol": 1, "id": "upper", "name": "Upper", "description": "Uppercases text",
+"category": "Text", "examples": [{"input": "hi", "description": "Shouts"}],
+"outputs": [{"key": "text", "description": "Uppercased input"}], "timeout": "2s"}`
+
+// writePlugin creates an executable shell script answering describe with
+// the given JSON and execute with the given shell commands
+func writePlugin(t *testing.T, dir, name, describe, execute string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts need a POSIX shell")
+	}
+
+	script := "#!/bin/sh\ncase \"$1\" in\ndescribe)\ncat <<'JSON'\n" + describe + "\nJSON\n;;\nexecute)\n" + execute + "\n;;\nesac\n"
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDescribes(t *testing.T) {
+	path := writePlugin(t, t.TempDir(), "upper", describeUpper, "")
+
+	p, err := Load(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if p.ID() != "upper" || p.Name() != "Upper" || p.Category() != "Text" {
+		t.Errorf("Unexpected description: %+v", p.desc)
+	}
+	if p.Timeout() != 2*time.Second {
+		t.Errorf("Expected 2s timeout, got %s", p.Timeout())
+	}
+	if ex := p.Examples(); len(ex) != 1 || ex[0].Input != "hi" {
+		t.Errorf("Unexpected examples: %+v", ex)
+	}
+	if out := p.Outputs(); len(out) != 1 || out[0].Key != "text" {
+		t.Errorf("Unexpected outputs: %+v", out)
+	}
+}
+
+func TestLoadRejectsInvalidDescriptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		describe string
+		want     string
+	}{
+		{"malformed", `{"id": `, "malformed response"},
+		{"empty", ``, "empty response"},
+		{"protocol", `{"protocol": 2, "id": "x"}`, "unsupported protocol version 2"},
+		{"id", `{"protocol": 1, "id": "Not Valid"}`, "invalid id"},
+		{"timeout", `{"protocol": 1, "id": "x", "timeout": "soon"}`, "invalid timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writePlugin(t, t.TempDir(), "p", tt.describe, "")
+			_, err := Load(context.Background(), path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	execute := `input=$(cat)
+case "$input" in
+*'"fail"'*) echo '{"error": "cannot shout that"}' ;;
+*'"garbage"'*) echo 'not json' ;;
+*'"nothing"'*) echo '{}' ;;
+*'"crash"'*) echo 'boom' >&2; exit 3 ;;
+*'"hang"'*) sleep 10 ;;
+*) echo '{"result": {"sections": [{"fields": [{"key": "text", "label": "Text", "value": "HI", "display": "HI"}]}]}}' ;;
+esac`
+	path := writePlugin(t, t.TempDir(), "upper", describeUpper, execute)
+
+	p, err := Load(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	result, err := p.ExecuteContext(context.Background(), "hi", nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if f, ok := result.Field("text"); !ok || f.Value != "HI" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	failures := []struct {
+		input string
+		want  string
+	}{
+		{"fail", "cannot shout that"},
+		{"garbage", "malformed response"},
+		{"nothing", "neither a result nor an error"},
+		{"crash", "exit status 3: boom"},
+	}
+	for _, tt := range failures {
+		_, err := p.ExecuteContext(context.Background(), tt.input, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Input %q: expected error containing %q, got %v", tt.input, tt.want, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := p.ExecuteContext(ctx, "hang", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the plugin to be killed, took %s", elapsed)
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{limit: 4}
+	b.Write([]byte("abc"))
+	b.Write([]byte("def"))
+
+	if b.String() != "abcd" || !b.truncated {
+		t.Errorf("Expected truncated buffer, got %q (%v)", b.String(), b.truncated)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// presetMode is the state of the preset picker in the execute view
+type presetMode int
+
+const (
+	presetsClosed presetMode = iota
+	presetsPicking
+	presetsNaming
+)
+
+// openPresets shows the preset picker for the selected feature
+func (c CLI) openPresets() (CLI, tea.Cmd) {
+	if c.presets == nil {
+		return c, nil
+	}
+
+	c.presetMode = presetsPicking
+	c.presetIndex = 0
+	c.blurInput()
+	return c, nil
+}
+
+// closePresets hides the preset picker and returns focus to the input
+func (c CLI) closePresets() (CLI, tea.Cmd) {
+	c.presetMode = presetsClosed
+	c.presetName.Blur()
+	return c, c.focusInput()
+}
+
+// updatePresets handles keys while the preset picker is open
+func (c CLI) updatePresets(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	if c.presetMode == presetsNaming {
+		return c.updatePresetName(msg)
+	}
+
+	id := c.selectedFeature.ID()
+	presets := c.presets.List(id)
+
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Back, c.keys.Presets):
+		return c.closePresets()
+
+	case key.Matches(msg, c.keys.Up):
+		if c.presetIndex > 0 {
+			c.presetIndex--
+		}
+
+	case key.Matches(msg, c.keys.Down):
+		if c.presetIndex < len(presets)-1 {
+			c.presetIndex++
+		}
+
+	case key.Matches(msg, c.keys.Select):
+		c, focus := c.closePresets()
+		if len(presets) == 0 {
+			return c, focus
+		}
+		c, cmd := c.replaceInput(presets[c.presetIndex].Input)
+		return c, tea.Batch(focus, cmd)
+
+	case key.Matches(msg, c.keys.PresetSave):
+		if strings.TrimSpace(c.inputValue()) == "" {
+			c.status = "Type an input before saving it as a preset"
+			return c, nil
+		}
+		c.presetMode = presetsNaming
+		c.presetName.SetValue("")
+		c.presetName.Focus()
+		return c, nil
+
+	case key.Matches(msg, c.keys.PresetDelete):
+		if len(presets) > 0 {
+			name := presets[c.presetIndex].Name
+			c.presets.Remove(id, name)
+			c.savePresets(fmt.Sprintf("Deleted preset %q", name))
+			c.presetIndex = max(0, min(c.presetIndex, len(c.presets.List(id))-1))
+		}
+	}
+
+	return c, nil
+}
+
+// updatePresetName handles typing the label of a new preset
+func (c CLI) updatePresetName(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Back):
+		c.presetMode = presetsPicking
+		c.presetName.Blur()
+		return c, nil
+
+	case key.Matches(msg, c.keys.Select):
+		name := c.presetName.Value()
+		if err := c.presets.Add(c.selectedFeature.ID(), name, c.inputValue()); err != nil {
+			c.status = err.Error()
+			return c, nil
+		}
+		c.savePresets(fmt.Sprintf("Saved preset %q", strings.TrimSpace(name)))
+		return c.closePresets()
+	}
+
+	var cmd tea.Cmd
+	c.presetName, cmd = c.presetName.Update(msg)
+	return c, cmd
+}
+
+// savePresets persists presets and reports the outcome in the status line
+func (c *CLI) savePresets(success string) {
+	if err := c.presets.Save(); err != nil {
+		c.status = err.Error()
+		return
+	}
+	c.status = success
+}
+
+// toggleFavorite pins or unpins a feature in the feature list
+func (c *CLI) toggleFavorite(id string) {
+	if c.presets == nil {
+		return
+	}
+
+	if c.presets.ToggleFavorite(id) {
+		c.savePresets(fmt.Sprintf("Pinned %s", id))
+	} else {
+		c.savePresets(fmt.Sprintf("Unpinned %s", id))
+	}
+}
+
+// renderPresets shows the presets of the selected feature
+func (c CLI) renderPresets() string {
+	var s strings.Builder
+
+	s.WriteString(c.styles.section.Render("Presets:") + "\n")
+
+	presets := c.presets.List(c.selectedFeature.ID())
+	if len(presets) == 0 {
+		s.WriteString(c.styles.help.Render("  No presets saved for this feature") + "\n")
+	}
+
+	width := 0
+	for _, p := range presets {
+		width = max(width, len(p.Name))
+	}
+	for i, p := range presets {
+		cursor := "  "
+		style := c.styles.feature
+		if i == c.presetIndex {
+			cursor = "→ "
+			style = c.styles.selectedFeature
+		}
+		s.WriteString(style.Render(fmt.Sprintf("%s%-*s  ", cursor, width, p.Name)) + c.styles.example.Render(oneLine(p.Input)) + "\n")
+	}
+
+	if c.presetMode == presetsNaming {
+		s.WriteString("\n" + c.styles.label.Render("Preset name: ") + c.presetName.View() + "\n")
+	}
+
+	return s.String()
+}
@@ -0,0 +1,89 @@
+package recording
+
+import (
+	"bhelper/feature"
+	"slices"
+	"strings"
+)
+
+// Difference is how a replayed run differs from its recording
+type Difference struct {
+	// OldError and NewError are set when the runs failed differently
+	OldError string
+	NewError string
+
+	// Fields lists the fields whose values changed
+	Fields []feature.FieldChange
+
+	// OldLines and NewLines hold the lines of the plain text output that
+	// changed, for differences outside the field values such as tables
+	OldLines []string
+	NewLines []string
+}
+
+// Diff compares a replayed run with its recording and returns nil when
+// both produced the same output. Fields with one of the ignored keys, such
+// as the current time, are left out of the comparison.
+func Diff(recorded, replayed Entry, ignore ...string) *Difference {
+	if recorded.Error != replayed.Error {
+		return &Difference{OldError: recorded.Error, NewError: replayed.Error}
+	}
+	if recorded.Error != "" {
+		return nil
+	}
+
+	old, new := recorded.Result, replayed.Result
+	if old == nil {
+		old = feature.NewResult("")
+	}
+	if new == nil {
+		new = feature.NewResult("")
+	}
+	old, new = without(old, ignore), without(new, ignore)
+
+	d := &Difference{Fields: feature.DiffFields(old, new)}
+	if len(d.Fields) == 0 {
+		d.OldLines, d.NewLines = changedLines(old.String(), new.String())
+		if len(d.OldLines) == 0 && len(d.NewLines) == 0 {
+			return nil
+		}
+	}
+	return d
+}
+
+// without returns a copy of r leaving out the fields with the given keys
+func without(r *feature.Result, keys []string) *feature.Result {
+	if len(keys) == 0 {
+		return r
+	}
+
+	stripped := &feature.Result{Title: r.Title, Sections: make([]*feature.Section, len(r.Sections))}
+	for i, s := range r.Sections {
+		copied := *s
+		copied.Fields = slices.DeleteFunc(slices.Clone(s.Fields), func(f feature.Field) bool {
+			return slices.Contains(keys, f.Key)
+		})
+		stripped.Sections[i] = &copied
+	}
+	return stripped
+}
+
+// changedLines compares two texts line by line and returns the lines of
+// each that differ at the same position
+func changedLines(old, new string) ([]string, []string) {
+	a, b := strings.Split(old, "\n"), strings.Split(new, "\n")
+
+	var removed, added []string
+	for i := range max(len(a), len(b)) {
+		switch {
+		case i >= len(a):
+			added = append(added, b[i])
+		case i >= len(b):
+			removed = append(removed, a[i])
+		case a[i] != b[i]:
+			removed = append(removed, a[i])
+			added = append(added, b[i])
+		}
+	}
+	return removed, added
+}
@@ -0,0 +1,99 @@
+package recording
+
+import (
+	"bhelper/feature"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	at := time.Now()
+	changed := sampleResult()
+	changed.Sections[0].Fields[0].Value = 91
+
+	table := func(cell string) *feature.Result {
+		r := feature.NewResult("")
+		r.AddSection("Generators").SetTable([]string{"Name", "Bits"}, [][]string{{"uuid", cell}})
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		recorded Entry
+		replayed Entry
+		ignore   []string
+		check    func(t *testing.T, d *Difference)
+	}{
+		{
+			name:     "same result",
+			recorded: NewEntry(at, "time", "90s", sampleResult(), nil),
+			replayed: NewEntry(at, "time", "90s", sampleResult(), nil),
+			check: func(t *testing.T, d *Difference) {
+				if d != nil {
+					t.Errorf("Expected no difference, got %+v", d)
+				}
+			},
+		},
+		{
+			name:     "same error",
+			recorded: NewEntry(at, "time", "5 secs", nil, errors.New("unknown unit")),
+			replayed: NewEntry(at, "time", "5 secs", nil, errors.New("unknown unit")),
+			check: func(t *testing.T, d *Difference) {
+				if d != nil {
+					t.Errorf("Expected no difference, got %+v", d)
+				}
+			},
+		},
+		{
+			name:     "changed field",
+			recorded: NewEntry(at, "time", "90s", sampleResult(), nil),
+			replayed: NewEntry(at, "time", "90s", changed, nil),
+			check: func(t *testing.T, d *Difference) {
+				if d == nil || len(d.Fields) != 1 || d.Fields[0].Key != "seconds" {
+					t.Fatalf("Expected the seconds field to differ, got %+v", d)
+				}
+				if got := feature.FormatValue(d.Fields[0].New.Value); got != "91" {
+					t.Errorf("Expected new value 91, got %s", got)
+				}
+			},
+		},
+		{
+			name:     "ignored field",
+			recorded: NewEntry(at, "time", "90s", sampleResult(), nil),
+			replayed: NewEntry(at, "time", "90s", changed, nil),
+			ignore:   []string{"seconds"},
+			check: func(t *testing.T, d *Difference) {
+				if d != nil {
+					t.Errorf("Expected the ignored field to be skipped, got %+v", d)
+				}
+			},
+		},
+		{
+			name:     "new error",
+			recorded: NewEntry(at, "time", "90s", sampleResult(), nil),
+			replayed: NewEntry(at, "time", "90s", nil, errors.New("timed out after 1s")),
+			check: func(t *testing.T, d *Difference) {
+				if d == nil || d.OldError != "" || d.NewError != "timed out after 1s" {
+					t.Errorf("Expected the new error, got %+v", d)
+				}
+			},
+		},
+		{
+			name:     "changed table",
+			recorded: NewEntry(at, "collision", "uuid", table("122"), nil),
+			replayed: NewEntry(at, "collision", "uuid", table("128"), nil),
+			check: func(t *testing.T, d *Difference) {
+				if d == nil || len(d.Fields) != 0 || len(d.OldLines) != 1 || len(d.NewLines) != 1 {
+					t.Fatalf("Expected one changed line, got %+v", d)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, Diff(tt.recorded, tt.replayed, tt.ignore...))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Package recording keeps a log of feature runs so they can be shared and
+// replayed later. The log is JSON Lines, one run per line, and is only ever
+// appended to.
+package recording
+
+import (
+	"bhelper/feature"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// maxEntrySize bounds a single line of a log
+const maxEntrySize = 16 << 20
+
+// Entry is a recorded feature run. Error is set instead of Result when the
+// run failed.
+type Entry struct {
+	Time    time.Time       `json:"time"`
+	Feature string          `json:"feature"`
+	Input   string          `json:"input"`
+	Result  *feature.Result `json:"result,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// NewEntry describes a run of a feature. The result is kept in the form it
+// is read back from a log, so values compare equal to recorded ones.
+func NewEntry(at time.Time, featureID, input string, result *feature.Result, err error) Entry {
+	e := Entry{Time: at, Feature: featureID, Input: input}
+	if err != nil {
+		e.Error = err.Error()
+		return e
+	}
+	e.Result = canonical(result)
+	return e
+}
+
+// canonical round-trips a result through JSON, with non-finite values as
+// text like in documents. Results that cannot be encoded are returned as
+// they are.
+func canonical(r *feature.Result) *feature.Result {
+	if r == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(feature.NewDocument("", "", r).Result())
+	if err != nil {
+		return r
+	}
+	var decoded feature.Result
+	if err := decode(data, &decoded); err != nil {
+		return r
+	}
+	return &decoded
+}
+
+// decode reads JSON keeping numbers as written, so large integers and
+// floats are not rounded or reformatted
+func decode(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// Recorder appends entries to a log
+type Recorder struct {
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewRecorder writes entries to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// Open appends entries to the log at path, creating it if needed
+func Open(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session log: %w", err)
+	}
+	return &Recorder{w: f, closer: f}, nil
+}
+
+// Record appends an entry as a single line
+func (r *Recorder) Record(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to record %s: %w", e.Feature, err)
+	}
+	if _, err := r.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write session log: %w", err)
+	}
+	return nil
+}
+
+// Close closes the log file opened by Open
+func (r *Recorder) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// Read returns the entries of a log in the order they were recorded.
+// Blank lines are skipped.
+func Read(r io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEntrySize)
+
+	var entries []Entry
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := decode(data, &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if e.Feature == "" {
+			return nil, fmt.Errorf("line %d: missing feature", line)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session log: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,122 @@
+package recording
+
+import (
+	"bhelper/feature"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleResult() *feature.Result {
+	result := feature.NewResult("Time Conversions (90s)")
+	result.AddSection("").
+		Add("seconds", "Seconds", 90).
+		Add("hours", "Hours", 0.025).
+		Add("probability", "Probability", 1e-30).
+		Add("nanoseconds", "Nanoseconds", int64(9007199254740993))
+	return result
+}
+
+func TestRecordRead(t *testing.T) {
+	at := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+
+	var b strings.Builder
+	r := NewRecorder(&b)
+	if err := r.Record(NewEntry(at, "time", "90s", sampleResult(), nil)); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := r.Record(NewEntry(at, "time", "5 secs", nil, errors.New("unknown unit"))); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if got := strings.Count(b.String(), "\n"); got != 2 {
+		t.Fatalf("Expected one line per entry, got %d:\n%s", got, b.String())
+	}
+
+	entries, err := Read(strings.NewReader(b.String() + "\n"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	first := entries[0]
+	if first.Feature != "time" || first.Input != "90s" || !first.Time.Equal(at) || first.Error != "" {
+		t.Errorf("Unexpected first entry %+v", first)
+	}
+	ns, _ := first.Result.Field("nanoseconds")
+	if got := feature.FormatValue(ns.Value); got != "9007199254740993" {
+		t.Errorf("Expected large integers to keep their precision, got %s", got)
+	}
+	if entries[1].Error != "unknown unit" || entries[1].Result != nil {
+		t.Errorf("Expected recorded error, got %+v", entries[1])
+	}
+}
+
+func TestRecordedResultsCompareEqual(t *testing.T) {
+	var b strings.Builder
+	if err := NewRecorder(&b).Record(NewEntry(time.Now(), "time", "90s", sampleResult(), nil)); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Read(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed := NewEntry(time.Now(), "time", "90s", sampleResult(), nil)
+	if d := Diff(entries[0], replayed); d != nil {
+		t.Errorf("Expected a fresh run to match its recording, got %+v", d)
+	}
+}
+
+func TestOpenAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	for _, input := range []string{"1s", "2s"} {
+		r, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		if err := r.Record(NewEntry(time.Now(), "time", input, sampleResult(), nil)); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := Read(f)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Input != "1s" || entries[1].Input != "2s" {
+		t.Errorf("Expected both runs in order, got %+v", entries)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want string
+	}{
+		{"invalid JSON", "{\"feature\":\"time\"}\n{", "line 2"},
+		{"missing feature", "{\"input\":\"90s\"}", "line 1: missing feature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.log))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bhelper/feature"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// renderResult renders a structured feature result with the theme styles
+func (st styles) renderResult(r *feature.Result) string {
+	return st.renderComparedResult(r, nil)
+}
+
+// renderComparedResult renders a result, highlighting the fields, table
+// cells and text lines that differ from other. Tables and text are matched
+// with the section at the same position. A nil other highlights nothing.
+func (st styles) renderComparedResult(r, other *feature.Result) string {
+	var blocks []string
+
+	if r.Title != "" {
+		blocks = append(blocks, st.resultTitle.Render(r.Title))
+	}
+
+	var d *sectionDiff
+	if other != nil {
+		d = &sectionDiff{changed: make(map[string]bool)}
+		for _, change := range feature.DiffFields(other, r) {
+			d.changed[change.Key] = true
+		}
+	}
+
+	for i, s := range r.Sections {
+		if d != nil {
+			d.other = &feature.Section{}
+			if i < len(other.Sections) {
+				d.other = other.Sections[i]
+			}
+		}
+		blocks = append(blocks, st.renderSection(s, d))
+	}
+
+	return strings.Join(blocks, "\n\n")
+}
+
+// sectionDiff is what a section is compared against: the keys of changed
+// fields and the section at the same position in the other result
+type sectionDiff struct {
+	changed map[string]bool
+	other   *feature.Section
+}
+
+// cellChanged reports whether a cell of t differs from the same cell of
+// the other section's table
+func (d *sectionDiff) cellChanged(t *feature.Table, row, col int) bool {
+	cell, ok := tableCell(t, row, col)
+	other, otherOK := tableCell(d.other.Table, row, col)
+	return ok != otherOK || cell != other
+}
+
+// tableCell returns a cell of a table, if there is one at that position
+func tableCell(t *feature.Table, row, col int) (string, bool) {
+	if t == nil || row >= len(t.Rows) || col >= len(t.Rows[row]) {
+		return "", false
+	}
+	return t.Rows[row][col], true
+}
+
+// renderSection renders a section heading followed by its fields, table
+// and text, highlighting differences when d is not nil
+func (st styles) renderSection(s *feature.Section, d *sectionDiff) string {
+	var lines []string
+
+	if s.Title != "" {
+		lines = append(lines, st.section.Render(s.Title))
+	}
+
+	width := 0
+	for _, f := range s.Fields {
+		width = max(width, lipgloss.Width(f.Label))
+	}
+	for _, f := range s.Fields {
+		label := st.fieldLabel.Width(width + 2).Render(f.Label + ":")
+		value := st.fieldValue
+		if d != nil && d.changed[f.Key] {
+			value = st.changed
+		}
+		lines = append(lines, label+value.Render(f.Display))
+	}
+
+	if s.Table != nil {
+		t := table.New().
+			Border(lipgloss.NormalBorder()).
+			BorderStyle(st.tableBorder).
+			Headers(s.Table.Columns...).
+			Rows(s.Table.Rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return st.tableHeader
+				}
+				if d != nil && d.cellChanged(s.Table, row, col) {
+					return st.tableCell.Inherit(st.changed)
+				}
+				return st.tableCell
+			})
+		lines = append(lines, t.Render())
+	}
+
+	if s.Text != "" {
+		text := strings.TrimRight(s.Text, "\n")
+		if d != nil {
+			text = st.highlightChangedLines(text, strings.TrimRight(d.other.Text, "\n"))
+		}
+		lines = append(lines, text)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// highlightChangedLines highlights the lines of text that differ from the
+// line at the same position in other
+func (st styles) highlightChangedLines(text, other string) string {
+	lines := strings.Split(text, "\n")
+	otherLines := strings.Split(other, "\n")
+	for i, line := range lines {
+		if i >= len(otherLines) || line != otherLines[i] {
+			lines[i] = st.changed.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bhelper/feature"
+	"bhelper/recording"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+)
+
+// replay runs the entries of a session log again and reports the ones
+// whose output changed since they were recorded. Fields the features mark
+// as volatile, and those given with --ignore, are not compared.
+func (r *commandRunner) replay(args []string) error {
+	fs := r.newFlagSet("replay")
+	timeout := fs.Duration("timeout", 0, "execution timeout per run")
+	ignore := fs.String("ignore", "", "comma-separated field keys not compared")
+
+	args, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("%w: replay requires a session log", errUsage)
+	}
+
+	var in io.Reader = r.stdin
+	if args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open session log: %w", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	entries, err := recording.Read(in)
+	if err != nil {
+		return fmt.Errorf("session log %s: %w", args[0], err)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(r.stdout, "No runs recorded")
+		return nil
+	}
+
+	var ignored []string
+	for _, key := range strings.Split(*ignore, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			ignored = append(ignored, key)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	changed := 0
+	for i, e := range entries {
+		replayed := r.replayEntry(ctx, e, *timeout)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		keys := ignored
+		if f, ok := r.registry.Get(e.Feature); ok {
+			keys = slices.Concat(ignored, feature.VolatileKeysOf(f, e.Input))
+		}
+		if d := recording.Diff(e, replayed, keys...); d != nil {
+			changed++
+			r.printDifference(i+1, e, d)
+		}
+	}
+
+	if changed > 0 {
+		return fmt.Errorf("%d of %d outputs changed", changed, len(entries))
+	}
+	fmt.Fprintf(r.stdout, "All %d runs unchanged\n", len(entries))
+	return nil
+}
+
+// replayEntry runs a recorded input again. Features that are no longer
+// registered are reported as a failed run.
+func (r *commandRunner) replayEntry(ctx context.Context, e recording.Entry, timeout time.Duration) recording.Entry {
+	f, err := r.lookup(e.Feature)
+	if err != nil {
+		return recording.NewEntry(time.Now(), e.Feature, e.Input, nil, err)
+	}
+
+	d := r.timeouts.For(f)
+	if timeout > 0 {
+		d = timeout
+	}
+
+	result, err := runFeature(ctx, f, e.Input, d, nil)
+	r.recordRun(f.ID(), e.Input, result, err)
+	return recording.NewEntry(time.Now(), f.ID(), e.Input, result, err)
+}
+
+// printDifference shows what changed in the n-th run of a log
+func (r *commandRunner) printDifference(n int, e recording.Entry, d *recording.Difference) {
+	heading := fmt.Sprintf("#%d %s %q", n, e.Feature, e.Input)
+	if !e.Time.IsZero() {
+		heading += fmt.Sprintf(" (recorded %s)", e.Time.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintln(r.stdout, heading)
+
+	if d.OldError != d.NewError {
+		fmt.Fprintf(r.stdout, "  error: %s → %s\n", orNone(d.OldError), orNone(d.NewError))
+	}
+	for _, c := range d.Fields {
+		fmt.Fprintf(r.stdout, "  %s: %s → %s\n", c.Label, fieldText(c.Old), fieldText(c.New))
+	}
+	for _, line := range d.OldLines {
+		fmt.Fprintf(r.stdout, "  - %s\n", line)
+	}
+	for _, line := range d.NewLines {
+		fmt.Fprintf(r.stdout, "  + %s\n", line)
+	}
+}
+
+// fieldText shows the raw value of a changed field, which may be missing
+func fieldText(f *feature.Field) string {
+	if f == nil {
+		return "(missing)"
+	}
+	return feature.FormatValue(f.Value)
+}
+
+// orNone shows an empty error as such
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
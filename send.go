@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bhelper/feature"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sendMode is the state of the "send output to feature" picker
+type sendMode int
+
+const (
+	sendClosed sendMode = iota
+	sendPickingField
+	sendPickingFeature
+)
+
+// openSend shows the fields of the last result that can be sent to
+// another feature, starting at the default output
+func (c CLI) openSend() (CLI, tea.Cmd) {
+	if c.result == nil {
+		c.status = "Nothing to send yet"
+		return c, nil
+	}
+
+	fields := c.result.Fields()
+	if len(fields) == 0 {
+		c.status = "This result has no fields to send"
+		return c, nil
+	}
+
+	c.sendMode = sendPickingField
+	c.sendIndex = 0
+	if outputs := feature.OutputsOf(c.selectedFeature); len(outputs) > 0 {
+		for i, f := range fields {
+			if f.Key == outputs[0].Key {
+				c.sendIndex = i
+			}
+		}
+	}
+	c.blurInput()
+	return c, nil
+}
+
+// closeSend hides the picker and returns focus to the input
+func (c CLI) closeSend() (CLI, tea.Cmd) {
+	c.sendMode = sendClosed
+	return c, c.focusInput()
+}
+
+// updateSend handles keys while picking the field and the target feature
+func (c CLI) updateSend(msg tea.KeyMsg) (CLI, tea.Cmd) {
+	if c.result == nil {
+		return c.closeSend()
+	}
+
+	count := len(c.result.Fields())
+	if c.sendMode == sendPickingFeature {
+		count = len(c.features)
+	}
+
+	switch {
+	case key.Matches(msg, c.keys.ForceQuit, c.keys.Send):
+		return c.closeSend()
+
+	case key.Matches(msg, c.keys.Back):
+		if c.sendMode == sendPickingFeature {
+			c.sendMode = sendPickingField
+			c.sendIndex = c.sendField
+			return c, nil
+		}
+		return c.closeSend()
+
+	case key.Matches(msg, c.keys.Up):
+		if c.sendIndex > 0 {
+			c.sendIndex--
+		}
+
+	case key.Matches(msg, c.keys.Down):
+		if c.sendIndex < count-1 {
+			c.sendIndex++
+		}
+
+	case key.Matches(msg, c.keys.Select):
+		if c.sendMode == sendPickingField {
+			c.sendMode = sendPickingFeature
+			c.sendField = c.sendIndex
+			c.sendIndex = 0
+			return c, nil
+		}
+		return c.sendTo(c.features[c.sendIndex])
+	}
+
+	return c, nil
+}
+
+// sendTo opens a feature with the picked field as input and runs it
+func (c CLI) sendTo(target feature.Feature) (CLI, tea.Cmd) {
+	field := c.result.Fields()[c.sendField]
+	source := c.selectedFeature.Name()
+
+	c.sendMode = sendClosed
+	c.cancelExecution()
+	c.clearOutput()
+	c.setInput("")
+	c.blurInput()
+
+	c.selectedFeature = target
+	c.historyIndex = -1
+	c.setInput(feature.FormatValue(field.Value))
+	c.status = fmt.Sprintf("Sent %s from %s", field.Label, source)
+
+	focus := c.focusInput()
+	c, cmd := c.startRecordedExecution()
+	return c, tea.Batch(focus, cmd)
+}
+
+// renderSend shows the field or feature picker
+func (c CLI) renderSend() string {
+	var s strings.Builder
+
+	var labels []string
+	if c.sendMode == sendPickingField {
+		s.WriteString(c.styles.section.Render("Send field:") + "\n")
+		for _, f := range c.result.Fields() {
+			labels = append(labels, fmt.Sprintf("%s: %s", f.Label, f.Display))
+		}
+	} else {
+		field := c.result.Fields()[c.sendField]
+		s.WriteString(c.styles.section.Render(fmt.Sprintf("Send %s to:", field.Label)) + "\n")
+		for _, f := range c.features {
+			labels = append(labels, f.Name())
+		}
+	}
+
+	for i, label := range labels {
+		cursor := "  "
+		style := c.styles.feature
+		if i == c.sendIndex {
+			cursor = "→ "
+			style = c.styles.selectedFeature
+		}
+		s.WriteString(style.Render(cursor+oneLine(label)) + "\n")
+	}
+	return s.String()
+}
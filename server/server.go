@@ -0,0 +1,325 @@
+// Package server exposes the feature registry over a local HTTP JSON API.
+package server
+
+import (
+	"bhelper/feature"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAddr only accepts connections from the local machine
+	DefaultAddr = "127.0.0.1:8080"
+
+	// DefaultMaxBodySize bounds the size of an execute request
+	DefaultMaxBodySize = 1 << 20
+
+	readHeaderTimeout = 5 * time.Second
+	readTimeout       = 30 * time.Second
+	idleTimeout       = 2 * time.Minute
+
+	// shutdownTimeout is how long in-flight requests get to finish once
+	// the server is asked to stop
+	shutdownTimeout = 10 * time.Second
+)
+
+// Server serves the features of a registry
+type Server struct {
+	registry    *feature.FeatureRegistry
+	timeout     func(feature.Feature) time.Duration
+	maxBodySize int64
+}
+
+// New creates a server for the features of registry. timeout returns the
+// execution timeout of a feature, nil uses the timeout the feature declares.
+func New(registry *feature.FeatureRegistry, timeout func(feature.Feature) time.Duration) *Server {
+	if timeout == nil {
+		timeout = feature.TimeoutOf
+	}
+	return &Server{
+		registry:    registry,
+		timeout:     timeout,
+		maxBodySize: DefaultMaxBodySize,
+	}
+}
+
+// SetMaxBodySize changes the largest accepted execute request, in bytes
+func (s *Server) SetMaxBodySize(n int64) {
+	s.maxBodySize = n
+}
+
+// Handler returns the HTTP handler of the API. Requests from web pages of
+// other sites and requests naming a host other than the server are refused,
+// so a page open in a browser cannot run features, not even through DNS
+// rebinding.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /features", s.listFeatures)
+	mux.HandleFunc("GET /features/{id}", s.getFeature)
+	mux.HandleFunc("POST /features/{id}/execute", s.execute)
+	return localOnly(mux)
+}
+
+// localOnly answers 403 to requests whose Host or Origin is not local
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLocal(r, r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q is not allowed", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host == "" || !isLocal(r, u.Host) {
+				writeError(w, http.StatusForbidden, fmt.Errorf("origin %q is not allowed", origin))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLocal reports whether host, with or without a port, is a loopback name
+// or the address the request was received on
+func isLocal(r *http.Request, host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	local, ok := r.Context().Value(http.LocalAddrContextKey).(*net.TCPAddr)
+	return ok && local.IP.Equal(ip)
+}
+
+// Serve accepts connections on l until ctx is done, then waits for
+// in-flight requests to finish before returning
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(l)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Summary describes a feature in the feature list
+type Summary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Live        bool   `json:"live"`
+	Multiline   bool   `json:"multiline"`
+}
+
+// Details describes a single feature with its help and examples
+type Details struct {
+	Summary
+	Tags     []string  `json:"tags"`
+	Help     string    `json:"help"`
+	Examples []Example `json:"examples"`
+	Outputs  []Output  `json:"outputs"`
+}
+
+// Example is a usage example of a feature
+type Example struct {
+	Input       string `json:"input"`
+	Description string `json:"description"`
+}
+
+// Output is a named result field of a feature
+type Output struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+}
+
+// ExecuteRequest is the body of an execute request
+type ExecuteRequest struct {
+	Input string `json:"input"`
+}
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func summarize(f feature.Feature) Summary {
+	return Summary{
+		ID:          f.ID(),
+		Name:        f.Name(),
+		Description: f.Description(),
+		Category:    feature.CategoryOf(f),
+		Live:        feature.IsLive(f),
+		Multiline:   feature.IsMultiline(f),
+	}
+}
+
+// listFeatures handles GET /features
+func (s *Server) listFeatures(w http.ResponseWriter, r *http.Request) {
+	features := s.registry.List()
+	summaries := make([]Summary, len(features))
+	for i, f := range features {
+		summaries[i] = summarize(f)
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// getFeature handles GET /features/{id}
+func (s *Server) getFeature(w http.ResponseWriter, r *http.Request) {
+	f, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	details := Details{
+		Summary:  summarize(f),
+		Tags:     feature.TagsOf(f),
+		Help:     f.Help(),
+		Examples: make([]Example, 0),
+		Outputs:  make([]Output, 0),
+	}
+	if details.Tags == nil {
+		details.Tags = make([]string, 0)
+	}
+	for _, ex := range f.Examples() {
+		details.Examples = append(details.Examples, Example{Input: ex.Input, Description: ex.Description})
+	}
+	for _, out := range feature.OutputsOf(f) {
+		details.Outputs = append(details.Outputs, Output{Key: out.Key, Description: out.Description})
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+// execute handles POST /features/{id}/execute. The input is read from a
+// JSON body {"input": "..."}.
+func (s *Server) execute(w http.ResponseWriter, r *http.Request) {
+	f, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	input, status, err := s.readInput(w, r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	timeout := s.timeout(f)
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	result, err := feature.Run(ctx, f, input, nil)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, fmt.Errorf("timed out after %s", timeout))
+	case r.Context().Err() != nil:
+		// The client went away, nobody is left to read a response
+	case err != nil:
+		writeError(w, http.StatusUnprocessableEntity, err)
+	default:
+		writeJSON(w, http.StatusOK, feature.NewDocument(f.ID(), input, result))
+	}
+}
+
+// readInput decodes the input of an execute request, returning the status
+// to answer with when it is invalid
+func (s *Server) readInput(w http.ResponseWriter, r *http.Request) (string, int, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", http.StatusRequestEntityTooLarge, fmt.Errorf("request body is larger than %d bytes", tooLarge.Limit)
+		}
+		return "", http.StatusBadRequest, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	mediaType := "application/json"
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mediaType, _, err = mime.ParseMediaType(ct); err != nil {
+			return "", http.StatusUnsupportedMediaType, fmt.Errorf("invalid content type %q", ct)
+		}
+	}
+
+	// Plain text is not accepted: browsers send it cross-origin without
+	// asking the server first
+	if mediaType != "application/json" {
+		return "", http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %q (expected application/json)", mediaType)
+	}
+
+	var req ExecuteRequest
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return "", http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err)
+	}
+	return req.Input, 0, nil
+}
+
+// lookup finds the feature named in the request path, answering 404 when
+// there is none
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (feature.Feature, bool) {
+	id := r.PathValue("id")
+	f, ok := s.registry.Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown feature %q", id))
+	}
+	return f, ok
+}
+
+// writeJSON encodes v before writing the status, so a value that cannot
+// be encoded is answered with an error instead of an empty body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(ErrorResponse{Error: fmt.Sprintf("failed to encode response: %v", err)})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,327 @@
+package server
+
+import (
+	"bhelper/feature"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echo returns its input in a single field and rejects "bad"
+type echo struct{}
+
+func (echo) ID() string          { return "echo" }
+func (echo) Name() string        { return "Echo" }
+func (echo) Description() string { return "Repeats the input" }
+func (echo) Help() string        { return "Type anything" }
+func (echo) Examples() []feature.Example {
+	return []feature.Example{{Input: "hi", Description: "Says hi"}}
+}
+func (echo) Outputs() []feature.Output {
+	return []feature.Output{{Key: "text", Description: "The input"}}
+}
+func (echo) Execute(input string) (string, error) {
+	return "", errors.New("use ExecuteContext")
+}
+func (echo) ExecuteContext(ctx context.Context, input string, progress feature.ProgressFunc) (*feature.Result, error) {
+	if input == "bad" {
+		return nil, errors.New("bad input")
+	}
+	result := feature.NewResult("")
+	if input == "inf" {
+		result.AddSection("").Add("text", "Text", math.Inf(1))
+		return result, nil
+	}
+	result.AddSection("").Add("text", "Text", input)
+	return result, nil
+}
+
+// slow blocks until its context is done
+type slow struct{ echo }
+
+func (slow) ID() string { return "slow" }
+func (slow) ExecuteContext(ctx context.Context, input string, progress feature.ProgressFunc) (*feature.Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	registry := feature.NewFeatureRegistry()
+	registry.Register(echo{})
+	registry.Register(slow{})
+
+	s := New(registry, func(f feature.Feature) time.Duration {
+		if f.ID() == "slow" {
+			return 20 * time.Millisecond
+		}
+		return time.Second
+	})
+	s.SetMaxBodySize(64)
+
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func decode[T any](t *testing.T, resp *http.Response) T {
+	t.Helper()
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON response, got %q", ct)
+	}
+	var v T
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return v
+}
+
+func TestListFeatures(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/features")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaries := decode[[]Summary](t, resp)
+
+	if len(summaries) != 2 || summaries[0].ID != "echo" || summaries[1].ID != "slow" {
+		t.Errorf("Expected features in registration order, got %+v", summaries)
+	}
+	if summaries[0].Category != feature.DefaultCategory {
+		t.Errorf("Expected default category, got %q", summaries[0].Category)
+	}
+}
+
+func TestGetFeature(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/features/echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	details := decode[Details](t, resp)
+
+	if details.Help != "Type anything" || len(details.Examples) != 1 || details.Examples[0].Input != "hi" {
+		t.Errorf("Unexpected details: %+v", details)
+	}
+	if len(details.Outputs) != 1 || details.Outputs[0].Key != "text" {
+		t.Errorf("Expected outputs, got %+v", details.Outputs)
+	}
+}
+
+func TestGetUnknownFeature(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/features/nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", resp.StatusCode)
+	}
+	if body := decode[ErrorResponse](t, resp); !strings.Contains(body.Error, "nope") {
+		t.Errorf("Expected error naming the feature, got %q", body.Error)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	ts := newTestServer(t)
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		status      int
+		want        string
+	}{
+		{"json", "/features/echo/execute", "application/json", `{"input":"hello"}`, http.StatusOK, "hello"},
+		{"no content type", "/features/echo/execute", "", `{"input":"hi"}`, http.StatusOK, "hi"},
+		{"infinite value", "/features/echo/execute", "application/json", `{"input":"inf"}`, http.StatusOK, "+Inf"},
+		{"feature error", "/features/echo/execute", "application/json", `{"input":"bad"}`, http.StatusUnprocessableEntity, "bad input"},
+		{"invalid json", "/features/echo/execute", "application/json", `{"input":`, http.StatusBadRequest, "invalid request body"},
+		{"unknown field", "/features/echo/execute", "application/json", `{"text":"x"}`, http.StatusBadRequest, "unknown field"},
+		{"too large", "/features/echo/execute", "application/json", `{"input":"` + strings.Repeat("x", 60) + `"}`, http.StatusRequestEntityTooLarge, "larger than 64 bytes"},
+		{"unsupported type", "/features/echo/execute", "application/xml", "<x/>", http.StatusUnsupportedMediaType, "unsupported content type"},
+		{"text", "/features/echo/execute", "text/plain; charset=utf-8", "plain\n", http.StatusUnsupportedMediaType, "unsupported content type"},
+		{"timeout", "/features/slow/execute", "application/json", `{"input":"x"}`, http.StatusGatewayTimeout, "timed out after 20ms"},
+		{"unknown feature", "/features/nope/execute", "application/json", `{"input":"x"}`, http.StatusNotFound, "unknown feature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, ts.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+
+			if tt.status == http.StatusOK {
+				doc := decode[feature.Document](t, resp)
+				if doc.Feature != "echo" || doc.Values["text"] != tt.want {
+					t.Errorf("Unexpected document: %+v", doc)
+				}
+				return
+			}
+			if body := decode[ErrorResponse](t, resp); !strings.Contains(body.Error, tt.want) {
+				t.Errorf("Expected error containing %q, got %q", tt.want, body.Error)
+			}
+		})
+	}
+}
+
+func TestWriteJSONEncodeError(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeJSON(w, http.StatusOK, map[string]any{"value": math.NaN()})
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	var body ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || !strings.Contains(body.Error, "unsupported value: NaN") {
+		t.Errorf("Expected an encoding error, got %q (%v)", w.Body.String(), err)
+	}
+}
+
+func TestRejectsForeignHost(t *testing.T) {
+	ts := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		host   string
+		status int
+	}{
+		{"server address", "", http.StatusOK},
+		{"localhost", "localhost:8080", http.StatusOK},
+		{"ipv6 loopback", "[::1]:8080", http.StatusOK},
+		{"rebound name", "attacker.example:8080", http.StatusForbidden},
+		{"other address", "192.0.2.1", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/features/echo/execute", strings.NewReader(`{"input":"hi"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRejectsForeignOrigin(t *testing.T) {
+	ts := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{"local page", "http://localhost:3000", http.StatusOK},
+		{"loopback page", "http://127.0.0.1:8080", http.StatusOK},
+		{"other site", "https://attacker.example", http.StatusForbidden},
+		{"opaque origin", "null", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/features/echo/execute", strings.NewReader(`{"input":"hi"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Origin", tt.origin)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if tt.status == http.StatusForbidden {
+				if body := decode[ErrorResponse](t, resp); !strings.Contains(body.Error, "not allowed") {
+					t.Errorf("Expected origin refusal, got %q", body.Error)
+				}
+				return
+			}
+			resp.Body.Close()
+		})
+	}
+}
+
+func TestExecuteWrongMethod(t *testing.T) {
+	ts := newTestServer(t)
+
+	resp, err := http.Get(ts.URL + "/features/echo/execute")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeShutsDown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := feature.NewFeatureRegistry()
+	registry.Register(echo{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- New(registry, nil).Serve(ctx, l)
+	}()
+
+	resp, err := http.Get("http://" + l.Addr().String() + "/features")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after cancellation")
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bhelper/config"
+	"bhelper/feature"
+	"bhelper/plugins"
+	"bhelper/theme"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// settings is the resolved configuration of a bhelper invocation
+type settings struct {
+	config   *config.Config
+	timeouts timeoutPolicy
+	keys     keyMap
+	themes   []theme.Theme
+
+	// warnings reports problems that do not prevent bhelper from starting,
+	// such as plugins that failed to load
+	warnings []error
+}
+
+// globalFlags are the flags that precede the subcommand
+type globalFlags struct {
+	config string
+	record string
+}
+
+// parseGlobalFlags extracts --config and --record from the start of the
+// arguments
+func parseGlobalFlags(args []string) (globalFlags, []string, error) {
+	var flags globalFlags
+	values := map[string]*string{
+		"--config": &flags.config,
+		"--record": &flags.record,
+	}
+
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		target, ok := values[name]
+		if !ok {
+			break
+		}
+
+		if hasValue {
+			args = args[1:]
+		} else {
+			if len(args) < 2 {
+				return globalFlags{}, nil, fmt.Errorf("%w: %s requires a path", errUsage, name)
+			}
+			value = args[1]
+			args = args[2:]
+		}
+		*target = value
+	}
+	return flags, args, nil
+}
+
+// loadSettings reads the configuration file and applies it to the
+// registered features, loading the plugins first when asked to. The file
+// named by --config or BHELPER_CONFIG must exist, the default one is
+// optional.
+func loadSettings(registry *feature.FeatureRegistry, configPath string, withPlugins bool) (*settings, error) {
+	mustExist := true
+	if configPath == "" {
+		configPath = os.Getenv("BHELPER_CONFIG")
+	}
+	if configPath == "" {
+		mustExist = false
+		path, err := config.DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		configPath = path
+	}
+
+	cfg, err := config.Load(configPath, mustExist)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	s := &settings{
+		config: cfg,
+		keys:   defaultKeyMap(),
+		timeouts: timeoutPolicy{
+			global:     time.Duration(cfg.Timeout),
+			perFeature: make(map[string]time.Duration),
+		},
+	}
+
+	var errs []error
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if themes, err := theme.Resolve(cfg.Themes); err == nil {
+		s.themes = themes
+	}
+	if err := s.keys.apply(cfg.Keys); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.timeouts.parse(os.Getenv("BHELPER_TIMEOUT")); err != nil {
+		errs = append(errs, fmt.Errorf("BHELPER_TIMEOUT: %w", err))
+	}
+
+	// Plugins are registered before feature settings are applied, so they
+	// can be configured like built-in features
+	if withPlugins {
+		s.warnings = registerPlugins(registry, cfg.Plugins)
+	}
+
+	ids := make([]string, 0, len(cfg.Features))
+	for id := range cfg.Features {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		fc := cfg.Features[id]
+		f, ok := registry.Get(id)
+		if !ok {
+			// The settings of plugins that were not loaded cannot be checked
+			if withPlugins {
+				errs = append(errs, fmt.Errorf("features.%s: unknown feature", id))
+			}
+			continue
+		}
+		if fc.Disabled {
+			registry.Unregister(id)
+			continue
+		}
+		if fc.Timeout > 0 {
+			if _, ok := s.timeouts.perFeature[id]; !ok {
+				s.timeouts.perFeature[id] = time.Duration(fc.Timeout)
+			}
+		}
+		if err := feature.Configure(f, fc.Options); err != nil {
+			errs = append(errs, fmt.Errorf("features.%s.options: %w", id, err))
+		}
+	}
+
+	if len(registry.List()) == 0 {
+		errs = append(errs, errors.New("features: every feature is disabled"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		indented := strings.ReplaceAll(err.Error(), "\n", "\n  ")
+		return nil, fmt.Errorf("invalid config %s:\n  %s", configPath, indented)
+	}
+	return s, nil
+}
+
+// registerPlugins loads the executables of the plugins directory into the
+// registry. Plugins that fail to load or reuse the ID of another feature
+// are skipped and reported.
+func registerPlugins(registry *feature.FeatureRegistry, cfg config.PluginsConfig) []error {
+	if cfg.Disabled {
+		return nil
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		var err error
+		if dir, err = plugins.DefaultDir(); err != nil {
+			return nil
+		}
+	}
+	dir, err := expandHome(dir)
+	if err != nil {
+		return []error{err}
+	}
+
+	loaded, errs := plugins.LoadDir(context.Background(), dir)
+	for _, p := range loaded {
+		if err := registry.Register(p); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", p.Path(), err))
+		}
+	}
+	return errs
+}
+
+// applyEnv overrides configuration values with environment variables
+func applyEnv(cfg *config.Config) error {
+	if v := os.Getenv("BHELPER_HISTORY_SIZE"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size <= 0 {
+			return fmt.Errorf("BHELPER_HISTORY_SIZE: expected a positive number, got %q", v)
+		}
+		cfg.UI.HistorySize = size
+	}
+	return nil
+}
+
+// cliOptions builds the options of the interactive interface
+func (s *settings) cliOptions() cliOptions {
+	return cliOptions{
+		timeouts:     s.timeouts,
+		keys:         s.keys,
+		undoSize:     s.config.UI.UndoSize,
+		inputWidth:   s.config.UI.InputWidth,
+		liveDebounce: time.Duration(s.config.UI.LiveDebounce),
+		themes:       s.themes,
+		theme:        theme.Select(s.config.UI.Theme, os.Getenv("NO_COLOR") != "", lipgloss.HasDarkBackground),
+		featureOrder: feature.SortOrder(s.config.UI.FeatureOrder),
+	}
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"bhelper/batch"
+	"bhelper/feature"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// completeFiles is printed by __complete instead of candidates when a
+// file name is expected, the scripts then complete paths
+const completeFiles = ":files"
+
+// flagSpec describes a command flag for shell completion. Every flag of
+// the subcommands takes a value.
+type flagSpec struct {
+	long        string
+	short       string
+	description string
+	// values returns the candidates for the value of the flag given the
+	// feature ID of the command line, nil when any value goes
+	values func(r *commandRunner, featureID string) []feature.Completion
+}
+
+// commandSpec describes a subcommand for shell completion
+type commandSpec struct {
+	name        string
+	description string
+	flags       []flagSpec
+	// features reports whether the arguments are completed from the
+	// registered features, which requires loading the plugins
+	features bool
+	// args returns the candidates for the positional argument following
+	// the given ones, files reports whether a file name fits there
+	args func(r *commandRunner, positional []string, word string) (candidates []feature.Completion, files bool)
+}
+
+// globalFlagSpecs lists the flags that precede the subcommand, all of
+// them take a file
+var globalFlagSpecs = []flagSpec{
+	{long: "config", description: "configuration file"},
+	{long: "record", description: "append feature runs to a session log"},
+}
+
+// commandSpecs lists the subcommands in the order of the usage text
+var commandSpecs = []commandSpec{
+	{
+		name:        "run",
+		description: "run a feature",
+		flags: []flagSpec{
+			{long: "output", short: "o", description: "output format", values: formatValues},
+			{long: "preset", short: "p", description: "use a saved preset as input", values: presetValues},
+			{long: "timeout", description: "abort the run after this long"},
+		},
+		features: true,
+		args:     completeFeatureInput,
+	},
+	{
+		name:        "batch",
+		description: "run a feature on every line of a file",
+		flags: []flagSpec{
+			{long: "output", short: "o", description: "output format", values: batchFormatValues},
+			{long: "jobs", short: "j", description: "number of inputs run at once"},
+			{long: "timeout", description: "abort each input after this long"},
+		},
+		features: true,
+		args: func(r *commandRunner, positional []string, word string) ([]feature.Completion, bool) {
+			if len(positional) == 0 {
+				return feature.MatchPrefix(word, r.featureIDs()), false
+			}
+			return nil, len(positional) == 1
+		},
+	},
+	{
+		name:        "serve",
+		description: "serve the features over a local HTTP JSON API",
+		flags: []flagSpec{
+			{long: "addr", description: "listen address"},
+			{long: "max-body", description: "largest accepted request body"},
+		},
+	},
+	{
+		name:        "replay",
+		description: "run a session log again and report changed outputs",
+		flags: []flagSpec{
+			{long: "ignore", description: "field keys not compared"},
+			{long: "timeout", description: "abort each run after this long"},
+		},
+		args: func(r *commandRunner, positional []string, word string) ([]feature.Completion, bool) {
+			return nil, len(positional) == 0
+		},
+	},
+	{name: "list", description: "list registered features"},
+	{name: "presets", description: "list saved presets", features: true, args: completeFeatureID},
+	{name: "stats", description: "show how often each feature was used"},
+	{name: "complete", description: "print the completions of an input", features: true, args: completeFeatureInput},
+	{
+		name:        "completion",
+		description: "print a shell completion script",
+		args: func(r *commandRunner, positional []string, word string) ([]feature.Completion, bool) {
+			if len(positional) > 0 {
+				return nil, false
+			}
+			return []feature.Completion{
+				{Value: "bash", Description: "Bash 4.4 or later"},
+				{Value: "zsh", Description: "Z shell"},
+				{Value: "fish", Description: "fish shell"},
+			}, false
+		},
+	},
+	{name: "help", description: "show usage or help for a feature", features: true, args: completeFeatureID},
+}
+
+// findCommand returns the completion spec of a subcommand
+func findCommand(name string) (commandSpec, bool) {
+	for _, spec := range commandSpecs {
+		if spec.name == name {
+			return spec, true
+		}
+	}
+	return commandSpec{}, false
+}
+
+// flag returns the flag an argument such as "-o" or "--output" names
+func (spec commandSpec) flag(arg string) (flagSpec, bool) {
+	name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+	for _, f := range spec.flags {
+		if name == f.long || (f.short != "" && name == f.short) {
+			return f, true
+		}
+	}
+	return flagSpec{}, false
+}
+
+// shellComplete prints the candidates for the last argument, the word
+// being completed, given the words before it. It backs the scripts of the
+// completion command, which pass the command line without "bhelper".
+func (r *commandRunner) shellComplete(args []string) error {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	candidates, files := r.completeWords(args[:len(args)-1], args[len(args)-1])
+	if files {
+		fmt.Fprintln(r.stdout, completeFiles)
+		return nil
+	}
+	for _, c := range candidates {
+		line := c.Value
+		if c.Description != "" {
+			line += "\t" + c.Description
+		}
+		fmt.Fprintln(r.stdout, line)
+	}
+	return nil
+}
+
+// needsPlugins reports whether a command uses the registered features.
+// Plugins are started to describe themselves, which the completion script
+// and shell completions that do not list features can do without.
+func needsPlugins(args []string) bool {
+	switch args[0] {
+	case "completion":
+		return false
+	case "__complete":
+		// The last argument is the word being completed
+		words := args[1:]
+		if len(words) > 0 {
+			words = words[:len(words)-1]
+		}
+		words, files := skipGlobalFlags(words)
+		if files || len(words) == 0 {
+			return false
+		}
+		spec, ok := findCommand(words[0])
+		return ok && spec.features
+	}
+	return true
+}
+
+// skipGlobalFlags returns the words following the global flags, files
+// reports whether the last word is a global flag waiting for its file
+func skipGlobalFlags(words []string) (rest []string, files bool) {
+	global := commandSpec{flags: globalFlagSpecs}
+	for len(words) > 0 && strings.HasPrefix(words[0], "--") {
+		name, _, hasValue := strings.Cut(words[0], "=")
+		if _, ok := global.flag(name); !ok {
+			break
+		}
+		if !hasValue {
+			if len(words) == 1 {
+				return nil, true
+			}
+			words = words[1:]
+		}
+		words = words[1:]
+	}
+	return words, false
+}
+
+// completeWords returns the candidates for word following words
+func (r *commandRunner) completeWords(words []string, word string) ([]feature.Completion, bool) {
+	words, files := skipGlobalFlags(words)
+	if files {
+		return nil, true
+	}
+
+	if len(words) == 0 {
+		if strings.HasPrefix(word, "-") {
+			candidates := make([]feature.Completion, len(globalFlagSpecs))
+			for i, f := range globalFlagSpecs {
+				candidates[i] = feature.Completion{Value: "--" + f.long, Description: f.description}
+			}
+			return feature.MatchPrefix(word, candidates), false
+		}
+		candidates := make([]feature.Completion, len(commandSpecs))
+		for i, spec := range commandSpecs {
+			candidates[i] = feature.Completion{Value: spec.name, Description: spec.description}
+		}
+		return feature.MatchPrefix(word, candidates), false
+	}
+
+	spec, ok := findCommand(words[0])
+	if !ok {
+		return nil, false
+	}
+
+	// Tell flags and their values apart from positional arguments
+	var positional []string
+	var pending *flagSpec
+	terminated := false
+	for _, w := range words[1:] {
+		switch {
+		case pending != nil:
+			pending = nil
+		case terminated || !strings.HasPrefix(w, "-") || w == "-":
+			positional = append(positional, w)
+		case w == "--":
+			terminated = true
+		case !strings.Contains(w, "="):
+			if f, ok := spec.flag(w); ok {
+				pending = &f
+			}
+		}
+	}
+
+	featureID := ""
+	if len(positional) > 0 {
+		featureID = positional[0]
+	}
+
+	if pending != nil {
+		if pending.values == nil {
+			return nil, false
+		}
+		return feature.MatchPrefix(word, pending.values(r, featureID)), false
+	}
+
+	if !terminated && strings.HasPrefix(word, "-") {
+		// --output=<value>
+		if name, value, ok := strings.Cut(word, "="); ok {
+			f, ok := spec.flag(name)
+			if !ok || f.values == nil {
+				return nil, false
+			}
+			var candidates []feature.Completion
+			for _, c := range feature.MatchPrefix(value, f.values(r, featureID)) {
+				candidates = append(candidates, feature.Completion{Value: name + "=" + c.Value, Description: c.Description})
+			}
+			return candidates, false
+		}
+
+		candidates := make([]feature.Completion, len(spec.flags))
+		for i, f := range spec.flags {
+			candidates[i] = feature.Completion{Value: "--" + f.long, Description: f.description}
+		}
+		return feature.MatchPrefix(word, candidates), false
+	}
+
+	if spec.args == nil {
+		return nil, false
+	}
+	return spec.args(r, positional, word)
+}
+
+// featureIDs returns the registered features in the configured order
+func (r *commandRunner) featureIDs() []feature.Completion {
+	var candidates []feature.Completion
+	for _, f := range r.registry.Sorted(r.order, usageCounts(r.usage)) {
+		candidates = append(candidates, feature.Completion{Value: f.ID(), Description: f.Name()})
+	}
+	return candidates
+}
+
+// completeFeatureID completes a single feature ID argument
+func completeFeatureID(r *commandRunner, positional []string, word string) ([]feature.Completion, bool) {
+	if len(positional) > 0 {
+		return nil, false
+	}
+	return feature.MatchPrefix(word, r.featureIDs()), false
+}
+
+// completeFeatureInput completes a feature ID followed by its input, the
+// first input word with the feature's examples, and every input word with
+// the feature's own completion. Those are not filtered by prefix since
+// features may match in other ways.
+func completeFeatureInput(r *commandRunner, positional []string, word string) ([]feature.Completion, bool) {
+	if len(positional) == 0 {
+		return feature.MatchPrefix(word, r.featureIDs()), false
+	}
+
+	f, ok := r.registry.Get(positional[0])
+	if !ok {
+		return nil, false
+	}
+
+	var candidates []feature.Completion
+	previous := positional[1:]
+	if len(previous) == 0 {
+		var examples []feature.Completion
+		for _, ex := range f.Examples() {
+			examples = append(examples, feature.Completion{Value: ex.Input, Description: ex.Description})
+		}
+		candidates = feature.MatchPrefix(word, examples)
+	}
+
+	// Input words are joined with spaces, only candidates within the last
+	// word can be offered for it
+	prefix := ""
+	if len(previous) > 0 {
+		prefix = strings.Join(previous, " ") + " "
+	}
+	input := prefix + word
+	completions := feature.Complete(f, input, len(input))
+	if completions.Start >= len(prefix) {
+		for i, c := range completions.Candidates {
+			value, _ := completions.Apply(input, i)
+			value = value[len(prefix):]
+			// An example may already offer the same value
+			if slices.ContainsFunc(candidates, func(c feature.Completion) bool { return c.Value == value }) {
+				continue
+			}
+			candidates = append(candidates, feature.Completion{Value: value, Description: c.Description})
+		}
+	}
+	return candidates, false
+}
+
+// formatValues returns the output formats of the run command
+func formatValues(r *commandRunner, featureID string) []feature.Completion {
+	var candidates []feature.Completion
+	for _, f := range feature.Formats {
+		candidates = append(candidates, feature.Completion{Value: string(f)})
+	}
+	return candidates
+}
+
+// batchFormatValues returns the output formats of the batch command
+func batchFormatValues(r *commandRunner, featureID string) []feature.Completion {
+	var candidates []feature.Completion
+	for _, f := range batch.Formats {
+		candidates = append(candidates, feature.Completion{Value: string(f)})
+	}
+	return candidates
+}
+
+// presetValues returns the presets saved for the feature
+func presetValues(r *commandRunner, featureID string) []feature.Completion {
+	var candidates []feature.Completion
+	for _, p := range r.presets.List(featureID) {
+		candidates = append(candidates, feature.Completion{Value: p.Name, Description: p.Input})
+	}
+	return candidates
+}
+
+// completionScript writes the completion script of a shell
+func (r *commandRunner) completionScript(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: completion requires a shell: bash, zsh or fish", errUsage)
+	}
+
+	var script string
+	switch args[0] {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return fmt.Errorf("%w: unsupported shell %q (expected bash, zsh or fish)", errUsage, args[0])
+	}
+	_, err := fmt.Fprint(r.stdout, script)
+	return err
+}
+
+// The scripts only collect the command line and call back into bhelper,
+// so new features and presets are completed without regenerating them.
+
+const bashCompletion = `# bash completion for bhelper
+# Load it with: source <(bhelper completion bash)
+
+_bhelper() {
+    local line=${COMP_LINE:0:COMP_POINT}
+    local cur=${line##*[[:space:]]}
+    local -a words out
+    read -ra words <<< "${line:0:${#line}-${#cur}}"
+    mapfile -t out < <(bhelper __complete "${words[@]:1}" "$cur" 2>/dev/null)
+
+    if [[ ${out[0]} == ":files" ]]; then
+        mapfile -t COMPREPLY < <(compgen -f -- "$cur")
+        return
+    fi
+
+    # Bash splits words at characters such as ':' and '=', only the part
+    # after the last of them is replaced
+    local keep=${cur%"${COMP_WORDS[COMP_CWORD]}"}
+    local candidate
+    COMPREPLY=()
+    for candidate in "${out[@]}"; do
+        candidate=${candidate%%$'\t'*}
+        [[ $candidate == "$keep"* ]] || continue
+        COMPREPLY+=("$(printf '%q' "${candidate#"$keep"}")")
+    done
+}
+
+complete -o nosort -F _bhelper bhelper
+`
+
+const zshCompletion = `#compdef bhelper
+# zsh completion for bhelper
+# Load it with: source <(bhelper completion zsh)
+
+_bhelper() {
+    local -a out candidates
+    local line value
+    out=("${(@f)$(bhelper __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}")
+
+    if [[ ${out[1]} == ":files" ]]; then
+        _files
+        return
+    fi
+
+    for line in "${out[@]}"; do
+        [[ -n $line ]] || continue
+        value=${line%%$'\t'*}
+        if [[ $line == *$'\t'* ]]; then
+            candidates+=("${value//:/\\:}:${line#*$'\t'}")
+        else
+            candidates+=("${value//:/\\:}")
+        fi
+    done
+    _describe -V bhelper candidates
+}
+
+if [[ $zsh_eval_context[-1] == loadautoload ]]; then
+    _bhelper "$@"
+else
+    compdef _bhelper bhelper
+fi
+`
+
+const fishCompletion = `# fish completion for bhelper
+# Load it with: bhelper completion fish | source
+
+function __bhelper_complete
+    set -l words (commandline -opc)
+    set -l current (commandline -ct)
+    set -l out (bhelper __complete $words[2..-1] "$current" 2>/dev/null)
+    if test "$out[1]" = ":files"
+        __fish_complete_path "$current"
+        return
+    end
+    printf '%s\n' $out
+end
+
+complete -c bhelper -f -k -a '(__bhelper_complete)'
+`
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bhelper/store"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestShellComplete(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"no arguments", nil, []string{"run", "batch", "serve", "replay", "list", "presets", "stats", "complete", "completion", "help"}},
+		{"subcommand", []string{"r"}, []string{"run", "replay"}},
+		{"global flags", []string{"-"}, []string{"--config", "--record"}},
+		{"global flag file", []string{"--config", ""}, []string{completeFiles}},
+		{"after global flags", []string{"--config", "bhelper.yaml", "--record=runs.jsonl", "ru"}, []string{"run"}},
+		{"feature ID", []string{"run", "t"}, []string{"time"}},
+		{"command flags", []string{"run", "-"}, []string{"--output", "--preset", "--timeout"}},
+		{"flag value", []string{"run", "-o", ""}, []string{"text", "json", "yaml", "csv"}},
+		{"inline flag value", []string{"run", "--output=y"}, []string{"--output=yaml"}},
+		{"batch flag value", []string{"batch", "--output", ""}, []string{"jsonl", "csv"}},
+		{"flag without values", []string{"run", "--timeout", ""}, nil},
+		{"preset", []string{"run", "time", "--preset", ""}, []string{"short"}},
+		{"preset before the feature", []string{"run", "-p", "short", "ti"}, []string{"time"}},
+		// Examples come first, the feature's candidates follow without repeating them
+		{"examples", []string{"run", "-p", "short", "time", "1"}, []string{"100ms", "1s", "1.5h", "1000ns", "1ns", "1us", "1ms", "1min", "1h"}},
+		{"feature completion", []string{"run", "time", "5m"}, []string{"5min", "5ms"}},
+		{"later input word", []string{"run", "time", "90", "m"}, []string{"ms", "min"}},
+		{"flags after --", []string{"run", "--", "--o"}, nil},
+		{"feature after --", []string{"run", "--", "c"}, []string{"character"}},
+		{"batch file", []string{"batch", "time", ""}, []string{completeFiles}},
+		{"batch after the file", []string{"batch", "time", "inputs.txt", ""}, nil},
+		{"replay file", []string{"replay", "--timeout", "1m", ""}, []string{completeFiles}},
+		{"shells", []string{"completion", ""}, []string{"bash", "zsh", "fish"}},
+		{"help", []string{"help", ""}, []string{"character", "time"}},
+		{"unknown command", []string{"launch", ""}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, stdout, _ := newTestRunner(t, "")
+			r.presets = store.NewPresets("")
+			if err := r.presets.Add("time", "short", "90s"); err != nil {
+				t.Fatal(err)
+			}
+
+			if code := r.execute(append([]string{"__complete"}, tt.args...)); code != exitOK {
+				t.Fatalf("Expected exit 0, got %d", code)
+			}
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n") {
+				if value, _, _ := strings.Cut(line, "\t"); value != "" {
+					got = append(got, value)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestShellCompleteDescriptions(t *testing.T) {
+	r, stdout, _ := newTestRunner(t, "")
+	r.execute([]string{"__complete", "run", "char"})
+
+	if got := stdout.String(); got != "character\tCharacter Analyzer\n" {
+		t.Errorf("Expected the feature name as description, got %q", got)
+	}
+}
+
+func TestNeedsPlugins(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"run", "time", "90s"}, true},
+		{[]string{"list"}, true},
+		{[]string{"completion", "bash"}, false},
+		{[]string{"__complete"}, false},
+		{[]string{"__complete", "r"}, false},
+		{[]string{"__complete", "--config", ""}, false},
+		{[]string{"__complete", "--config", "bhelper.yaml", "run", ""}, true},
+		{[]string{"__complete", "help", ""}, true},
+		{[]string{"__complete", "completion", ""}, false},
+		{[]string{"__complete", "replay", ""}, false},
+		{[]string{"__complete", "launch", ""}, false},
+	}
+
+	for _, tt := range tests {
+		if got := needsPlugins(tt.args); got != tt.want {
+			t.Errorf("needsPlugins(%q): expected %v, got %v", tt.args, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultHistorySize is the number of inputs kept per feature
+const DefaultHistorySize = 500
+
+// HistoryFile is the name of the input history file in the state directory
+const HistoryFile = "history.json"
+
+// InputHistory keeps the inputs executed for each feature, oldest first.
+// Re-running an input moves it to the end instead of storing it twice.
+type InputHistory struct {
+	path    string
+	maxSize int
+	entries map[string][]string
+}
+
+// NewInputHistory creates an empty history that is saved to path. An empty
+// path keeps the history in memory only.
+func NewInputHistory(path string, maxSize int) *InputHistory {
+	if maxSize <= 0 {
+		maxSize = DefaultHistorySize
+	}
+	return &InputHistory{
+		path:    path,
+		maxSize: maxSize,
+		entries: make(map[string][]string),
+	}
+}
+
+// LoadInputHistory reads the history saved at path. A missing file yields
+// an empty history.
+func LoadInputHistory(path string, maxSize int) (*InputHistory, error) {
+	h := NewInputHistory(path, maxSize)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		return h, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	if h.entries == nil {
+		h.entries = make(map[string][]string)
+	}
+
+	for id := range h.entries {
+		h.trim(id)
+	}
+	return h, nil
+}
+
+// DefaultHistoryPath returns the history file location in the state directory
+func DefaultHistoryPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, HistoryFile), nil
+}
+
+// Add records an executed input for a feature
+func (h *InputHistory) Add(featureID, input string) {
+	if strings.TrimSpace(input) == "" {
+		return
+	}
+
+	entries := h.entries[featureID]
+	for i, e := range entries {
+		if e == input {
+			entries = append(entries[:i:i], entries[i+1:]...)
+			break
+		}
+	}
+
+	h.entries[featureID] = append(entries, input)
+	h.trim(featureID)
+}
+
+// Entries returns the inputs recorded for a feature, oldest first
+func (h *InputHistory) Entries(featureID string) []string {
+	return h.entries[featureID]
+}
+
+// Search looks backwards from index before (exclusive) for the most recent
+// entry of a feature containing query. It returns the index of the match.
+func (h *InputHistory) Search(featureID, query string, before int) (int, bool) {
+	entries := h.entries[featureID]
+	if before > len(entries) {
+		before = len(entries)
+	}
+
+	for i := before - 1; i >= 0; i-- {
+		if strings.Contains(entries[i], query) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Save writes the history to its file
+func (h *InputHistory) Save() error {
+	if h.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(h.path, data); err != nil {
+		return fmt.Errorf("failed to save history: %w", err)
+	}
+	return nil
+}
+
+// trim drops the oldest entries of a feature beyond the maximum size
+func (h *InputHistory) trim(featureID string) {
+	if entries := h.entries[featureID]; len(entries) > h.maxSize {
+		h.entries[featureID] = entries[len(entries)-h.maxSize:]
+	}
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestInputHistoryAddDeduplicates(t *testing.T) {
+	h := NewInputHistory("", 10)
+	h.Add("time", "1s")
+	h.Add("time", "5min")
+	h.Add("time", "1s")
+	h.Add("time", "   ")
+
+	want := []string{"5min", "1s"}
+	if got := h.Entries("time"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestInputHistoryMaxSize(t *testing.T) {
+	h := NewInputHistory("", 2)
+	h.Add("time", "1s")
+	h.Add("time", "2s")
+	h.Add("time", "3s")
+
+	want := []string{"2s", "3s"}
+	if got := h.Entries("time"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestInputHistoryPerFeature(t *testing.T) {
+	h := NewInputHistory("", 10)
+	h.Add("time", "1s")
+	h.Add("character", "Hello")
+
+	if got := h.Entries("time"); len(got) != 1 || got[0] != "1s" {
+		t.Errorf("Expected [1s], got %v", got)
+	}
+	if got := h.Entries("timezone"); len(got) != 0 {
+		t.Errorf("Expected no entries, got %v", got)
+	}
+}
+
+func TestInputHistorySearch(t *testing.T) {
+	h := NewInputHistory("", 10)
+	h.Add("collision", "base62:10:5000/sec")
+	h.Add("collision", "base64:8:100/min")
+	h.Add("collision", "base62:8:1/ms")
+
+	i, ok := h.Search("collision", "base62", 3)
+	if !ok || i != 2 {
+		t.Errorf("Expected match at 2, got %d (%v)", i, ok)
+	}
+
+	i, ok = h.Search("collision", "base62", i)
+	if !ok || i != 0 {
+		t.Errorf("Expected match at 0, got %d (%v)", i, ok)
+	}
+
+	if _, ok := h.Search("collision", "snowflake", 3); ok {
+		t.Error("Expected no match")
+	}
+}
+
+func TestInputHistorySaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", HistoryFile)
+
+	h := NewInputHistory(path, 10)
+	h.Add("time", "1s")
+	h.Add("time", "100ms")
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadInputHistory(path, 1)
+	if err != nil {
+		t.Fatalf("LoadInputHistory failed: %v", err)
+	}
+	if got := loaded.Entries("time"); !reflect.DeepEqual(got, []string{"100ms"}) {
+		t.Errorf("Expected [100ms] after trimming, got %v", got)
+	}
+}
+
+func TestLoadInputHistoryMissingFile(t *testing.T) {
+	h, err := LoadInputHistory(filepath.Join(t.TempDir(), "missing.json"), 10)
+	if err != nil {
+		t.Fatalf("Expected no error for missing file, got %v", err)
+	}
+	if len(h.Entries("time")) != 0 {
+		t.Error("Expected empty history")
+	}
+}
+
+func TestStateDirHonorsXDG(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+
+	got, err := StateDir()
+	if err != nil {
+		t.Fatalf("StateDir failed: %v", err)
+	}
+	if want := filepath.Join(dir, appName); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PresetsFile is the name of the presets file in the config directory
+const PresetsFile = "presets.yaml"
+
+// Preset is a named input saved for a feature
+type Preset struct {
+	Name  string `yaml:"name"`
+	Input string `yaml:"input"`
+}
+
+// Presets holds favorite features and saved inputs, stored as YAML in the
+// config directory so they can also be edited by hand
+type Presets struct {
+	path      string
+	Favorites []string            `yaml:"favorites,omitempty"`
+	Inputs    map[string][]Preset `yaml:"presets,omitempty"`
+}
+
+// NewPresets creates an empty preset store saved to path. An empty path
+// keeps presets in memory only.
+func NewPresets(path string) *Presets {
+	return &Presets{
+		path:   path,
+		Inputs: make(map[string][]Preset),
+	}
+}
+
+// LoadPresets reads the presets saved at path. A missing file yields an
+// empty store.
+func LoadPresets(path string) (*Presets, error) {
+	p := NewPresets(path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("failed to read presets: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return NewPresets(path), fmt.Errorf("failed to parse presets %s: %w", path, err)
+	}
+	if p.Inputs == nil {
+		p.Inputs = make(map[string][]Preset)
+	}
+	return p, nil
+}
+
+// DefaultPresetsPath returns the presets file location in the config directory
+func DefaultPresetsPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, PresetsFile), nil
+}
+
+// List returns the presets of a feature in the order they were saved
+func (p *Presets) List(featureID string) []Preset {
+	return p.Inputs[featureID]
+}
+
+// Find returns the preset of a feature with the given name
+func (p *Presets) Find(featureID, name string) (Preset, bool) {
+	for _, preset := range p.Inputs[featureID] {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// Add saves a preset, replacing any existing preset with the same name
+func (p *Presets) Add(featureID, name, input string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("preset name must not be empty")
+	}
+
+	presets := p.Inputs[featureID]
+	for i, preset := range presets {
+		if preset.Name == name {
+			presets[i].Input = input
+			return nil
+		}
+	}
+
+	p.Inputs[featureID] = append(presets, Preset{Name: name, Input: input})
+	return nil
+}
+
+// Remove deletes the preset of a feature with the given name
+func (p *Presets) Remove(featureID, name string) {
+	p.Inputs[featureID] = slices.DeleteFunc(p.Inputs[featureID], func(preset Preset) bool {
+		return preset.Name == name
+	})
+	if len(p.Inputs[featureID]) == 0 {
+		delete(p.Inputs, featureID)
+	}
+}
+
+// IsFavorite reports whether a feature is pinned
+func (p *Presets) IsFavorite(featureID string) bool {
+	return slices.Contains(p.Favorites, featureID)
+}
+
+// ToggleFavorite pins or unpins a feature and reports whether it is now pinned
+func (p *Presets) ToggleFavorite(featureID string) bool {
+	if i := slices.Index(p.Favorites, featureID); i >= 0 {
+		p.Favorites = slices.Delete(p.Favorites, i, i+1)
+		return false
+	}
+	p.Favorites = append(p.Favorites, featureID)
+	return true
+}
+
+// Save writes the presets to their file
+func (p *Presets) Save() error {
+	if p.path == "" {
+		return nil
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(p.path, data); err != nil {
+		return fmt.Errorf("failed to save presets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPresetsAddFindRemove(t *testing.T) {
+	p := NewPresets("")
+
+	if err := p.Add("collision", "daily", "base62:10:5000/sec"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := p.Add("collision", "daily", "base62:12:5000/sec"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if got := p.List("collision"); len(got) != 1 {
+		t.Fatalf("Expected replaced preset, got %v", got)
+	}
+
+	preset, ok := p.Find("collision", "daily")
+	if !ok || preset.Input != "base62:12:5000/sec" {
+		t.Errorf("Expected updated input, got %+v (%v)", preset, ok)
+	}
+
+	p.Remove("collision", "daily")
+	if _, ok := p.Find("collision", "daily"); ok {
+		t.Error("Expected preset to be removed")
+	}
+}
+
+func TestPresetsAddEmptyName(t *testing.T) {
+	p := NewPresets("")
+	if err := p.Add("time", "  ", "1s"); err == nil {
+		t.Error("Expected error for empty name")
+	}
+}
+
+func TestPresetsToggleFavorite(t *testing.T) {
+	p := NewPresets("")
+
+	if !p.ToggleFavorite("time") || !p.IsFavorite("time") {
+		t.Error("Expected time to be pinned")
+	}
+	if p.ToggleFavorite("time") || p.IsFavorite("time") {
+		t.Error("Expected time to be unpinned")
+	}
+}
+
+func TestPresetsSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", PresetsFile)
+
+	p := NewPresets(path)
+	p.ToggleFavorite("collision")
+	if err := p.Add("collision", "daily", "base62:10:5000/sec"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := p.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadPresets(path)
+	if err != nil {
+		t.Fatalf("LoadPresets failed: %v", err)
+	}
+	if !loaded.IsFavorite("collision") {
+		t.Error("Expected collision to be a favorite")
+	}
+	if preset, ok := loaded.Find("collision", "daily"); !ok || preset.Input != "base62:10:5000/sec" {
+		t.Errorf("Expected saved preset, got %+v (%v)", preset, ok)
+	}
+}
+
+func TestLoadPresetsInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), PresetsFile)
+	if err := os.WriteFile(path, []byte("presets: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadPresets(path); err == nil {
+		t.Error("Expected parse error")
+	}
+}
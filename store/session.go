@@ -0,0 +1,85 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SessionFile is the name of the workspace tabs file in the state directory
+const SessionFile = "session.json"
+
+// Tab is a workspace tab kept between launches. A tab without a feature
+// shows the feature list.
+type Tab struct {
+	FeatureID string `json:"feature,omitempty"`
+	Input     string `json:"input,omitempty"`
+}
+
+// Session holds the tabs that were open when the interactive interface
+// was last closed
+type Session struct {
+	path   string
+	Tabs   []Tab `json:"tabs"`
+	Active int   `json:"active"`
+}
+
+// NewSession creates an empty session saved to path. An empty path keeps
+// it in memory only.
+func NewSession(path string) *Session {
+	return &Session{path: path}
+}
+
+// LoadSession reads the session saved at path. A missing file yields an
+// empty session.
+func LoadSession(path string) (*Session, error) {
+	s := NewSession(path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return NewSession(path), fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+	s.Active = min(max(s.Active, 0), max(len(s.Tabs)-1, 0))
+	return s, nil
+}
+
+// DefaultSessionPath returns the session file location in the state
+// directory
+func DefaultSessionPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SessionFile), nil
+}
+
+// Set replaces the saved tabs and the index of the tab in front
+func (s *Session) Set(tabs []Tab, active int) {
+	s.Tabs = tabs
+	s.Active = min(max(active, 0), max(len(tabs)-1, 0))
+}
+
+// Save writes the session to its file
+func (s *Session) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
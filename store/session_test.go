@@ -0,0 +1,66 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSessionSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", SessionFile)
+	tabs := []Tab{
+		{FeatureID: "time", Input: "90s"},
+		{},
+		{FeatureID: "character", Input: "line one\nline two"},
+	}
+
+	s := NewSession(path)
+	s.Set(tabs, 2)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Tabs, tabs) || loaded.Active != 2 {
+		t.Errorf("Expected saved tabs with the third in front, got %+v (active %d)", loaded.Tabs, loaded.Active)
+	}
+}
+
+func TestSessionActiveInRange(t *testing.T) {
+	s := NewSession("")
+	s.Set([]Tab{{FeatureID: "time"}}, 5)
+	if s.Active != 0 {
+		t.Errorf("Expected active tab clamped to 0, got %d", s.Active)
+	}
+
+	path := filepath.Join(t.TempDir(), SessionFile)
+	if err := os.WriteFile(path, []byte(`{"tabs":[{"feature":"time"},{}],"active":7}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession failed: %v", err)
+	}
+	if loaded.Active != 1 {
+		t.Errorf("Expected active tab clamped to 1, got %d", loaded.Active)
+	}
+}
+
+func TestLoadSessionErrors(t *testing.T) {
+	s, err := LoadSession(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || len(s.Tabs) != 0 {
+		t.Errorf("Expected empty session for missing file, got %+v (%v)", s.Tabs, err)
+	}
+
+	path := filepath.Join(t.TempDir(), SessionFile)
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSession(path); err == nil {
+		t.Error("Expected error for corrupt file")
+	}
+}
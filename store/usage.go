@@ -0,0 +1,121 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// UsageFile is the name of the usage statistics file in the state directory
+const UsageFile = "usage.json"
+
+// FeatureUsage counts the runs of a feature
+type FeatureUsage struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Usage keeps local usage statistics per feature ID. They are only used to
+// order the feature list and are never sent anywhere.
+type Usage struct {
+	path     string
+	features map[string]FeatureUsage
+}
+
+// NewUsage creates empty statistics that are saved to path. An empty path
+// keeps them in memory only.
+func NewUsage(path string) *Usage {
+	return &Usage{
+		path:     path,
+		features: make(map[string]FeatureUsage),
+	}
+}
+
+// LoadUsage reads the statistics saved at path. A missing file yields empty
+// statistics.
+func LoadUsage(path string) (*Usage, error) {
+	u := NewUsage(path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return u, nil
+	}
+	if err != nil {
+		return u, fmt.Errorf("failed to read usage statistics: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &u.features); err != nil {
+		return u, fmt.Errorf("failed to parse usage statistics %s: %w", path, err)
+	}
+	if u.features == nil {
+		u.features = make(map[string]FeatureUsage)
+	}
+	return u, nil
+}
+
+// DefaultUsagePath returns the statistics file location in the state
+// directory, next to the input history
+func DefaultUsagePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, UsageFile), nil
+}
+
+// Record counts a run of a feature at the given time
+func (u *Usage) Record(featureID string, at time.Time) {
+	fu := u.features[featureID]
+	fu.Count++
+	if at.After(fu.LastUsed) {
+		fu.LastUsed = at
+	}
+	u.features[featureID] = fu
+}
+
+// Get returns the statistics of a feature
+func (u *Usage) Get(featureID string) FeatureUsage {
+	return u.features[featureID]
+}
+
+// Count returns how often a feature was run
+func (u *Usage) Count(featureID string) int {
+	return u.features[featureID].Count
+}
+
+// Recent returns up to n features, most recently used first
+func (u *Usage) Recent(n int) []string {
+	ids := make([]string, 0, len(u.features))
+	for id := range u.features {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := u.features[ids[i]].LastUsed, u.features[ids[j]].LastUsed
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return ids[i] < ids[j]
+	})
+	return ids[:min(n, len(ids))]
+}
+
+// Save writes the statistics to their file
+func (u *Usage) Save() error {
+	if u.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(u.features, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(u.path, data); err != nil {
+		return fmt.Errorf("failed to save usage statistics: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUsageRecord(t *testing.T) {
+	u := NewUsage("")
+	start := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+
+	u.Record("time", start)
+	u.Record("time", start.Add(time.Minute))
+	u.Record("time", start) // out of order, keeps the latest time
+
+	got := u.Get("time")
+	if got.Count != 3 || !got.LastUsed.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected 3 uses last at 12:01, got %+v", got)
+	}
+	if u.Count("collision") != 0 {
+		t.Error("Expected unused feature to have no uses")
+	}
+}
+
+func TestUsageOrder(t *testing.T) {
+	u := NewUsage("")
+	start := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+
+	u.Record("character", start)
+	u.Record("time", start.Add(time.Minute))
+	u.Record("time", start.Add(2*time.Minute))
+	u.Record("collision", start.Add(3*time.Minute))
+	u.Record("timezone", start.Add(3*time.Minute))
+
+	for id, want := range map[string]int{"time": 2, "collision": 1, "timezone": 1, "character": 1, "unused": 0} {
+		if got := u.Count(id); got != want {
+			t.Errorf("Expected %d uses of %s, got %d", want, id, got)
+		}
+	}
+	if got, want := u.Recent(3), []string{"collision", "timezone", "time"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected most recent first %v, got %v", want, got)
+	}
+	if got := NewUsage("").Recent(3); len(got) != 0 {
+		t.Errorf("Expected no recent features, got %v", got)
+	}
+}
+
+func TestUsageSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", UsageFile)
+	at := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+
+	u := NewUsage(path)
+	u.Record("time", at)
+	if err := u.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadUsage(path)
+	if err != nil {
+		t.Fatalf("LoadUsage failed: %v", err)
+	}
+	if got := loaded.Get("time"); got.Count != 1 || !got.LastUsed.Equal(at) {
+		t.Errorf("Expected saved statistics, got %+v", got)
+	}
+}
+
+func TestLoadUsageErrors(t *testing.T) {
+	u, err := LoadUsage(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || len(u.Recent(10)) != 0 {
+		t.Errorf("Expected empty statistics for missing file, got %v (%v)", u.Recent(10), err)
+	}
+
+	path := filepath.Join(t.TempDir(), UsageFile)
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadUsage(path); err == nil {
+		t.Error("Expected error for corrupt file")
+	}
+}
@@ -1,36 +1,135 @@
 package main
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"bhelper/theme"
 
-var (
-	titleStyle = lipgloss.NewStyle().
+	"github.com/charmbracelet/lipgloss"
+)
+
+// styles holds the lipgloss styles derived from the active theme
+type styles struct {
+	title           lipgloss.Style
+	feature         lipgloss.Style
+	selectedFeature lipgloss.Style
+	category        lipgloss.Style
+	match           lipgloss.Style
+	section         lipgloss.Style
+	label           lipgloss.Style
+	example         lipgloss.Style
+	help            lipgloss.Style
+	spinner         lipgloss.Style
+	status          lipgloss.Style
+	invalid         lipgloss.Style
+	outputBox       lipgloss.Style
+	resultTitle     lipgloss.Style
+	fieldLabel      lipgloss.Style
+	fieldValue      lipgloss.Style
+	tableHeader     lipgloss.Style
+	tableCell       lipgloss.Style
+	tableBorder     lipgloss.Style
+	changed         lipgloss.Style
+	tab             lipgloss.Style
+	activeTab       lipgloss.Style
+}
+
+// newStyles builds the styles of a theme
+func newStyles(t theme.Theme) styles {
+	return styles{
+		title: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("12")).
-			MarginBottom(1)
+			Foreground(color(t.Title)).
+			MarginBottom(1),
+
+		feature: lipgloss.NewStyle().
+			Foreground(color(t.Text)),
 
-	featureStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("7"))
+		selectedFeature: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(color(t.Selected)),
+
+		category: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(color(t.Category)),
 
-	selectedFeatureStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("10"))
+		match: lipgloss.NewStyle().
+			Underline(true).
+			Foreground(color(t.Match)),
 
-	sectionStyle = lipgloss.NewStyle().
+		section: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("14"))
+			Foreground(color(t.Section)),
 
-	labelStyle = lipgloss.NewStyle().
-			Bold(true)
+		label: lipgloss.NewStyle().
+			Bold(true),
 
-	exampleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("11"))
+		example: lipgloss.NewStyle().
+			Foreground(color(t.Example)),
 
-	helpStyle = lipgloss.NewStyle().
-			Faint(true).
-			Foreground(lipgloss.Color("8"))
+		// Without a muted color help text is set apart by intensity
+		help: lipgloss.NewStyle().
+			Faint(t.Muted == "").
+			Foreground(color(t.Muted)),
 
-	outputBoxStyle = lipgloss.NewStyle().
+		spinner: lipgloss.NewStyle().
+			Foreground(color(t.Accent)),
+
+		status: lipgloss.NewStyle().
+			Foreground(color(t.Status)),
+
+		invalid: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(color(t.Status)),
+
+		outputBox: lipgloss.NewStyle().
 			Padding(1, 2).
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("8"))
-)
+			BorderForeground(color(t.Border)),
+
+		resultTitle: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true),
+
+		fieldLabel: lipgloss.NewStyle().
+			Foreground(color(t.Text)),
+
+		fieldValue: lipgloss.NewStyle().
+			Bold(true),
+
+		tableHeader: lipgloss.NewStyle().
+			Bold(true).
+			Padding(0, 1).
+			Foreground(color(t.Section)),
+
+		tableCell: lipgloss.NewStyle().
+			Padding(0, 1),
+
+		tableBorder: lipgloss.NewStyle().
+			Foreground(color(t.Border)),
+
+		// Differences stay visible without colors through the underline
+		changed: lipgloss.NewStyle().
+			Bold(true).
+			Underline(t.Accent == "").
+			Foreground(color(t.Accent)),
+
+		tab: lipgloss.NewStyle().
+			Padding(0, 1).
+			Faint(t.Muted == "").
+			Foreground(color(t.Muted)),
+
+		// The underline marks the tab in front without colors
+		activeTab: lipgloss.NewStyle().
+			Padding(0, 1).
+			Bold(true).
+			Underline(true).
+			Foreground(color(t.Selected)),
+	}
+}
+
+// color converts a theme color, leaving the terminal default for empty ones
+func color(c string) lipgloss.TerminalColor {
+	if c == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(c)
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bhelper/feature"
+	"bhelper/store"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxTabs is the number of workspace tabs that can be open at once
+const maxTabs = 9
+
+// tab is the state of a workspace tab: its feature with the input, result
+// and undo history. The tab in front is kept in the CLI fields, the others
+// are stored until they are switched to.
+type tab struct {
+	mode            CLIMode
+	selectedIndex   int
+	selectedFeature feature.Feature
+	filterInput     textinput.Model
+	textInput       textinput.Model
+	textArea        textarea.Model
+	output          string
+	result          *feature.Result
+	resultInput     string
+	format          feature.Format
+	history         *History
+	historyIndex    int
+	historyDraft    string
+	viewport        viewport.Model
+	running         bool
+	runID           int
+	recordRun       int
+	cancelRun       context.CancelFunc
+	progress        float64
+}
+
+// newTab returns an empty tab showing the feature list
+func (c CLI) newTab() tab {
+	// Setting a value never shares the text of the copied inputs, so they
+	// keep their prompt and width only
+	fi := c.filterInput
+	fi.SetValue("")
+	ti := c.textInput
+	ti.SetValue("")
+
+	return tab{
+		mode:         ModeFeatureList,
+		filterInput:  fi,
+		textInput:    ti,
+		textArea:     newTextArea(),
+		format:       feature.FormatText,
+		history:      NewHistory(c.undoSize),
+		historyIndex: -1,
+		viewport:     viewport.New(0, 0),
+	}
+}
+
+// saveTab returns the state of the tab in front
+func (c CLI) saveTab() tab {
+	return tab{
+		mode:            c.mode,
+		selectedIndex:   c.selectedIndex,
+		selectedFeature: c.selectedFeature,
+		filterInput:     c.filterInput,
+		textInput:       c.textInput,
+		textArea:        c.textArea,
+		output:          c.output,
+		result:          c.result,
+		resultInput:     c.resultInput,
+		format:          c.format,
+		history:         c.history,
+		historyIndex:    c.historyIndex,
+		historyDraft:    c.historyDraft,
+		viewport:        c.viewport,
+		running:         c.running,
+		runID:           c.runID,
+		recordRun:       c.recordRun,
+		cancelRun:       c.cancelRun,
+		progress:        c.progress,
+	}
+}
+
+// loadTab brings the state of a tab to the front
+func (c *CLI) loadTab(t tab) {
+	c.mode = t.mode
+	c.selectedIndex = t.selectedIndex
+	c.selectedFeature = t.selectedFeature
+	c.filterInput = t.filterInput
+	c.textInput = t.textInput
+	c.textArea = t.textArea
+	c.output = t.output
+	c.result = t.result
+	c.resultInput = t.resultInput
+	c.format = t.format
+	c.history = t.history
+	c.historyIndex = t.historyIndex
+	c.historyDraft = t.historyDraft
+	c.viewport = t.viewport
+	c.running = t.running
+	c.runID = t.runID
+	c.recordRun = t.recordRun
+	c.cancelRun = t.cancelRun
+	c.progress = t.progress
+}
+
+// name labels the tab in the tab bar
+func (t tab) name() string {
+	if t.mode == ModeFeatureList || t.selectedFeature == nil {
+		return "Features"
+	}
+	return t.selectedFeature.Name()
+}
+
+// input returns the input of the tab's feature
+func (t tab) input() string {
+	if t.selectedFeature != nil && feature.IsMultiline(t.selectedFeature) {
+		return t.textArea.Value()
+	}
+	return t.textInput.Value()
+}
+
+// closePanels closes the pickers and prompts that belong to the view rather
+// than to a tab
+func (c *CLI) closePanels() {
+	c.filtering = false
+	c.filterInput.Blur()
+	c.searching = false
+	c.presetMode = presetsClosed
+	c.presetName.Blur()
+	c.exportMode = exportClosed
+	c.savePath.Blur()
+	c.loadingFile = false
+	c.loadPath.Blur()
+	c.sendMode = sendClosed
+	c.outputFocused = false
+	c.outputSearching = false
+	c.outputQuery.Blur()
+	c.outputQuery.SetValue("")
+	c.blurInput()
+}
+
+// openTab adds a tab showing the feature list after the one in front
+func (c CLI) openTab() (CLI, tea.Cmd) {
+	if len(c.tabs) >= maxTabs {
+		c.status = fmt.Sprintf("At most %d tabs can be open", maxTabs)
+		return c, nil
+	}
+
+	c.closePanels()
+	c.tabs[c.activeTab] = c.saveTab()
+	c.activeTab++
+	c.tabs = slices.Insert(c.tabs, c.activeTab, c.newTab())
+	c.loadTab(c.tabs[c.activeTab])
+	return c.showTab()
+}
+
+// switchTab brings tab i to the front. Runs of the other tabs go on in the
+// background.
+func (c CLI) switchTab(i int) (CLI, tea.Cmd) {
+	if i == c.activeTab {
+		return c, nil
+	}
+
+	c.closePanels()
+	c.tabs[c.activeTab] = c.saveTab()
+	c.activeTab = i
+	c.loadTab(c.tabs[i])
+	return c.showTab()
+}
+
+// closeTab cancels the run of the tab in front and removes it. The last tab
+// stays open.
+func (c CLI) closeTab() (CLI, tea.Cmd) {
+	if len(c.tabs) < 2 {
+		c.status = "The last tab cannot be closed"
+		return c, nil
+	}
+
+	c.closePanels()
+	c.cancelExecution()
+	c.tabs = slices.Delete(c.tabs, c.activeTab, c.activeTab+1)
+	c.activeTab = min(c.activeTab, len(c.tabs)-1)
+	c.loadTab(c.tabs[c.activeTab])
+	return c.showTab()
+}
+
+// showTab prepares the tab just brought to the front: its inputs follow the
+// window and theme, which may have changed while it was hidden, and a live
+// feature without a result runs again
+func (c CLI) showTab() (CLI, tea.Cmd) {
+	// Pending live runs belong to the tab that was in front
+	c.editSeq++
+	c.completionIndex = 0
+	c.hideCompletion = true
+
+	if c.width > 0 {
+		c.sizeInputs()
+	}
+	if c.result != nil {
+		c.output = c.renderOutput()
+	}
+
+	var cmds []tea.Cmd
+	if c.mode == ModeFeatureExecute {
+		cmds = append(cmds, c.focusInput())
+	}
+	switch {
+	case c.running:
+		cmds = append(cmds, c.spinner.Tick)
+	case c.output == "" && c.liveReady():
+		cmds = append(cmds, liveTick(0, c.editSeq))
+	}
+	return c, tea.Batch(cmds...)
+}
+
+// liveReady reports whether the tab in front shows a live feature whose
+// input can run
+func (c CLI) liveReady() bool {
+	return c.mode == ModeFeatureExecute && feature.IsLive(c.selectedFeature) &&
+		c.inputValue() != "" && c.validateInput() == nil
+}
+
+// tabOfRun returns the hidden tab that started a run, or -1
+func (c CLI) tabOfRun(runID int) int {
+	for i, t := range c.tabs {
+		if i != c.activeTab && t.running && t.runID == runID {
+			return i
+		}
+	}
+	return -1
+}
+
+// inTab applies update to hidden tab i as if it were in front
+func (c CLI) inTab(i int, update func(CLI) CLI) CLI {
+	front := c.saveTab()
+	c.loadTab(c.tabs[i])
+	c = update(c)
+	c.tabs[i] = c.saveTab()
+	c.loadTab(front)
+	return c
+}
+
+// restoreTabs reopens the tabs of the last session. Tabs of features that
+// are no longer registered show the feature list.
+func (c *CLI) restoreTabs(s *store.Session) {
+	if s == nil || len(s.Tabs) == 0 {
+		return
+	}
+
+	saved := s.Tabs[:min(len(s.Tabs), maxTabs)]
+	tabs := make([]tab, 0, len(saved))
+	for _, st := range saved {
+		c.loadTab(c.newTab())
+		if f, ok := c.registry.Get(st.FeatureID); ok {
+			c.selectedFeature = f
+			c.mode = ModeFeatureExecute
+			c.setInput(st.Input)
+		}
+		tabs = append(tabs, c.saveTab())
+	}
+
+	c.tabs = tabs
+	c.activeTab = min(s.Active, len(tabs)-1)
+	c.loadTab(c.tabs[c.activeTab])
+	if c.mode == ModeFeatureExecute {
+		c.focusInput()
+	}
+}
+
+// sessionTabs returns the open tabs as they are saved between launches,
+// with the index of the tab in front
+func (c CLI) sessionTabs() ([]store.Tab, int) {
+	tabs := slices.Clone(c.tabs)
+	tabs[c.activeTab] = c.saveTab()
+
+	saved := make([]store.Tab, len(tabs))
+	for i, t := range tabs {
+		if t.mode != ModeFeatureList && t.selectedFeature != nil {
+			saved[i] = store.Tab{FeatureID: t.selectedFeature.ID(), Input: t.input()}
+		}
+	}
+	return saved, c.activeTab
+}
+
+// tabHelp returns the tab bindings worth showing in help lines
+func (c CLI) tabHelp() []key.Binding {
+	if len(c.tabs) < 2 {
+		return []key.Binding{c.keys.NewTab}
+	}
+	return []key.Binding{c.keys.NewTab, c.keys.NextTab, c.keys.CloseTab}
+}
+
+// renderTabBar shows the open tabs above the view once there is more than
+// one. Tabs running in the background are marked.
+func (c CLI) renderTabBar() string {
+	if len(c.tabs) < 2 {
+		return ""
+	}
+
+	parts := make([]string, len(c.tabs))
+	for i, t := range c.tabs {
+		style := c.styles.tab
+		if i == c.activeTab {
+			t = c.saveTab()
+			style = c.styles.activeTab
+		}
+
+		label := fmt.Sprintf("%d %s", i+1, t.name())
+		if t.running {
+			label += " …"
+		}
+		parts[i] = style.Render(label)
+	}
+
+	bar := strings.Join(parts, c.styles.help.Render("│"))
+	if c.width > 0 {
+		bar = lipgloss.NewStyle().MaxWidth(c.width).Render(bar)
+	}
+	return bar + "\n\n"
+}
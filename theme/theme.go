@@ -0,0 +1,240 @@
+// Package theme defines the color palettes of the interactive interface.
+// Themes are plain data so users can write their own in the configuration
+// file, optionally starting from a built-in one.
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Names of the built-in themes
+const (
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high-contrast"
+	Monochrome   = "monochrome"
+
+	// Auto picks dark or light from the terminal background, or monochrome
+	// when NO_COLOR is set
+	Auto = "auto"
+)
+
+// Theme is a named palette. Colors are ANSI color numbers ("12") or hex
+// values ("#5f87ff"); an empty color keeps the terminal default.
+type Theme struct {
+	Name    string `yaml:"-"`
+	Extends string `yaml:"extends,omitempty"`
+
+	Title    string `yaml:"title,omitempty"`
+	Text     string `yaml:"text,omitempty"`
+	Selected string `yaml:"selected,omitempty"`
+	Category string `yaml:"category,omitempty"`
+	Match    string `yaml:"match,omitempty"`
+	Section  string `yaml:"section,omitempty"`
+	Example  string `yaml:"example,omitempty"`
+	Muted    string `yaml:"muted,omitempty"`
+	Accent   string `yaml:"accent,omitempty"`
+	Status   string `yaml:"status,omitempty"`
+	Border   string `yaml:"border,omitempty"`
+}
+
+// colors maps the configuration name of every color to its field
+func (t *Theme) colors() map[string]*string {
+	return map[string]*string{
+		"title":    &t.Title,
+		"text":     &t.Text,
+		"selected": &t.Selected,
+		"category": &t.Category,
+		"match":    &t.Match,
+		"section":  &t.Section,
+		"example":  &t.Example,
+		"muted":    &t.Muted,
+		"accent":   &t.Accent,
+		"status":   &t.Status,
+		"border":   &t.Border,
+	}
+}
+
+// Builtin returns the built-in themes in display order
+func Builtin() []Theme {
+	return []Theme{
+		{
+			Name:     Dark,
+			Title:    "12",
+			Text:     "7",
+			Selected: "10",
+			Category: "13",
+			Match:    "11",
+			Section:  "14",
+			Example:  "11",
+			Muted:    "8",
+			Accent:   "13",
+			Status:   "11",
+			Border:   "8",
+		},
+		{
+			Name:     Light,
+			Title:    "25",
+			Text:     "236",
+			Selected: "28",
+			Category: "90",
+			Match:    "166",
+			Section:  "30",
+			Example:  "130",
+			Muted:    "244",
+			Accent:   "90",
+			Status:   "130",
+			Border:   "248",
+		},
+		{
+			Name:     HighContrast,
+			Title:    "15",
+			Text:     "15",
+			Selected: "11",
+			Category: "14",
+			Match:    "10",
+			Section:  "14",
+			Example:  "11",
+			Muted:    "7",
+			Accent:   "11",
+			Status:   "11",
+			Border:   "15",
+		},
+		{
+			Name: Monochrome,
+		},
+	}
+}
+
+// Resolve returns the built-in themes followed by the custom ones sorted by
+// name. A custom theme starts from the theme it extends, or from
+// monochrome, and overrides the colors it sets. Every invalid theme is
+// reported at once.
+func Resolve(custom map[string]Theme) ([]Theme, error) {
+	themes := Builtin()
+	builtin := make(map[string]Theme, len(themes))
+	for _, t := range themes {
+		builtin[t.Name] = t
+	}
+
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		t, err := resolve(name, custom, builtin, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("themes.%s: %w", name, err))
+			continue
+		}
+		if _, ok := builtin[name]; ok {
+			themes[Index(themes, name)] = t
+			continue
+		}
+		themes = append(themes, t)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return themes, nil
+}
+
+// resolve merges a custom theme onto its base. seen guards against cycles
+// between custom themes.
+func resolve(name string, custom, builtin map[string]Theme, seen []string) (Theme, error) {
+	for _, s := range seen {
+		if s == name {
+			return Theme{}, fmt.Errorf("extends itself through %q", seen[0])
+		}
+	}
+
+	t := custom[name]
+	for key, c := range t.colors() {
+		if !validColor(*c) {
+			return Theme{}, fmt.Errorf("%s: invalid color %q", key, *c)
+		}
+	}
+
+	var base Theme
+	switch _, isCustom := custom[t.Extends]; {
+	case t.Extends == "":
+		base = builtin[Monochrome]
+	case isCustom && t.Extends != name:
+		b, err := resolve(t.Extends, custom, builtin, append(seen, name))
+		if err != nil {
+			return Theme{}, err
+		}
+		base = b
+	default:
+		b, ok := builtin[t.Extends]
+		if !ok {
+			return Theme{}, fmt.Errorf("extends unknown theme %q", t.Extends)
+		}
+		base = b
+	}
+
+	overrides := t.colors()
+	for key, c := range base.colors() {
+		if v := *overrides[key]; v != "" {
+			*c = v
+		}
+	}
+	base.Name = name
+	base.Extends = t.Extends
+	return base, nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor reports whether c is empty, an ANSI 256 color number or a hex
+// color
+func validColor(c string) bool {
+	if c == "" || hexColor.MatchString(c) {
+		return true
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// Index returns the position of the named theme, or -1
+func Index(themes []Theme, name string) int {
+	for i, t := range themes {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Exists reports whether name is Auto, a built-in theme or a custom one
+func Exists(name string, custom map[string]Theme) bool {
+	if _, ok := custom[name]; ok || name == Auto {
+		return true
+	}
+	return Index(Builtin(), name) >= 0
+}
+
+// Select resolves a configured theme name. Auto yields monochrome when
+// noColor is set and otherwise follows the terminal background, which is
+// only queried when needed.
+func Select(name string, noColor bool, darkBackground func() bool) string {
+	if name != Auto && name != "" {
+		return name
+	}
+	switch {
+	case noColor:
+		return Monochrome
+	case darkBackground():
+		return Dark
+	default:
+		return Light
+	}
+}
@@ -0,0 +1,82 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveCustomThemes(t *testing.T) {
+	themes, err := Resolve(map[string]Theme{
+		"solar": {Extends: Light, Title: "#b58900"},
+		"night": {Extends: "solar", Text: "252"},
+		"plain": {Selected: "2"},
+		"dark":  {Extends: Dark, Border: "240"},
+	})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	names := make([]string, len(themes))
+	for i, th := range themes {
+		names[i] = th.Name
+	}
+	want := "dark,light,high-contrast,monochrome,night,plain,solar"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("Resolve() names = %s, want %s", got, want)
+	}
+
+	night := themes[Index(themes, "night")]
+	if night.Title != "#b58900" || night.Text != "252" || night.Selected != "28" {
+		t.Errorf("night = %+v, want inherited solar and light colors", night)
+	}
+	if plain := themes[Index(themes, "plain")]; plain.Selected != "2" || plain.Title != "" {
+		t.Errorf("plain = %+v, want monochrome base", plain)
+	}
+	if dark := themes[Index(themes, Dark)]; dark.Border != "240" || dark.Title != "12" {
+		t.Errorf("dark = %+v, want overridden border", dark)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	_, err := Resolve(map[string]Theme{
+		"bad":   {Title: "blue"},
+		"loop":  {Extends: "loop2"},
+		"loop2": {Extends: "loop"},
+		"lost":  {Extends: "nope"},
+	})
+	if err == nil {
+		t.Fatal("Resolve() expected error")
+	}
+
+	for _, want := range []string{
+		`themes.bad: title: invalid color "blue"`,
+		"themes.loop: extends itself",
+		`themes.lost: extends unknown theme "nope"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Resolve() error = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name    string
+		noColor bool
+		dark    bool
+		want    string
+	}{
+		{Auto, false, true, Dark},
+		{Auto, false, false, Light},
+		{Auto, true, true, Monochrome},
+		{"", false, true, Dark},
+		{HighContrast, true, true, HighContrast},
+	}
+
+	for _, tt := range tests {
+		dark := func() bool { return tt.dark }
+		if got := Select(tt.name, tt.noColor, dark); got != tt.want {
+			t.Errorf("Select(%q, %v, %v) = %s, want %s", tt.name, tt.noColor, tt.dark, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// applyTheme makes the theme at index i active and restyles the components
+// and the rendered output
+func (c *CLI) applyTheme(i int) {
+	t := c.themes[i]
+	c.themeIndex = i
+	c.styles = newStyles(t)
+	c.spinner.Style = c.styles.spinner
+
+	c.progressBar = progress.New(progress.WithSolidFill(t.Accent), progress.WithWidth(40))
+	c.progressBar.EmptyColor = t.Muted
+
+	if c.result != nil {
+		c.output = c.renderOutput()
+	}
+}
+
+// nextTheme switches to the next theme in the list
+func (c CLI) nextTheme() (tea.Model, tea.Cmd) {
+	c.applyTheme((c.themeIndex + 1) % len(c.themes))
+	c.status = fmt.Sprintf("Theme: %s", c.themes[c.themeIndex].Name)
+	return c, nil
+}
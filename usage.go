@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bhelper/feature"
+	"bhelper/store"
+	"fmt"
+	"time"
+)
+
+// recentCount is the number of features in the recent section of the list
+const recentCount = 3
+
+// usageCounts returns the run count of each feature for the most-used
+// order, nil when usage is not tracked
+func usageCounts(u *store.Usage) func(id string) int {
+	if u == nil {
+		return nil
+	}
+	return u.Count
+}
+
+// recordUsage counts a run of the selected feature
+func (c *CLI) recordUsage() {
+	if c.usage == nil {
+		return
+	}
+
+	c.usage.Record(c.selectedFeature.ID(), time.Now())
+	if err := c.usage.Save(); err != nil {
+		c.status = fmt.Sprintf("Usage statistics not saved: %v", err)
+	}
+}
+
+// recentFeatures returns the most recently used features shown at the top
+// of the list. Pinned features are left out since they are listed right
+// below.
+func (c CLI) recentFeatures() []feature.Feature {
+	if c.usage == nil {
+		return nil
+	}
+
+	var recent []feature.Feature
+	for _, id := range c.usage.Recent(recentCount + len(c.features)) {
+		f, ok := c.registry.Get(id)
+		if !ok || c.isFavorite(id) {
+			continue
+		}
+		if recent = append(recent, f); len(recent) == recentCount {
+			break
+		}
+	}
+	return recent
+}